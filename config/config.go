@@ -23,6 +23,38 @@ const (
 
 	// DefaultLogLevel defines a default log level as INFO.
 	DefaultLogLevel = "info"
+
+	// SidecarBundlePolicyEvict drops bundles that were reaped but not committed
+	SidecarBundlePolicyEvict = "evict"
+	// SidecarBundlePolicyRequeue moves the uncommitted txs of a reaped bundle
+	// to the next height
+	SidecarBundlePolicyRequeue = "requeue"
+
+	// SidecarCurrentHeightEligible keeps accepting bundles for the auction
+	// height once the proposer started reaping it, for later reaps to include
+	SidecarCurrentHeightEligible = "eligible"
+	// SidecarCurrentHeightReject rejects bundles for the auction height once
+	// the proposer started reaping it
+	SidecarCurrentHeightReject = "reject"
+
+	// SidecarBundleChecksQueue has bundles wait for a slot when
+	// max_concurrent_bundle_checks bundles are already being validated
+	SidecarBundleChecksQueue = "queue"
+	// SidecarBundleChecksReject rejects bundles when
+	// max_concurrent_bundle_checks bundles are already being validated
+	SidecarBundleChecksReject = "reject"
+
+	// SidecarPinnedBundleKeep never evicts bundles with pinned txs to get back
+	// under the sidecar's limits
+	SidecarPinnedBundleKeep = "keep"
+	// SidecarPinnedBundleEvict evicts bundles with pinned txs like any other,
+	// counting them separately
+	SidecarPinnedBundleEvict = "evict"
+
+	// SidecarStarvedBundleEvict evicts starved bundles
+	SidecarStarvedBundleEvict = "evict"
+	// SidecarStarvedBundlePrioritize reaps starved bundles ahead of the others
+	SidecarStarvedBundlePrioritize = "prioritize"
 )
 
 // NOTE: Most of the structs & relevant comments + the
@@ -144,7 +176,7 @@ func (cfg *Config) ValidateBasic() error {
 	if err := cfg.Instrumentation.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [instrumentation] section: %w", err)
 	}
-	if err := cfg.Sidecar.ValidateBasic(); err != nil {
+	if err := cfg.Sidecar.Validate(); err != nil {
 		return fmt.Errorf("error in [Sidecar] section: %w", err)
 	}
 	return nil
@@ -1093,24 +1125,227 @@ type SidecarConfig struct {
 	RootDir         string `mapstructure:"home"`
 	RelayerID       string `mapstructure:"relayer_id"`
 	PersonalPeerIDs string `mapstructure:"personal_peer_ids"`
+	// What to do with the txs of a reaped bundle that didn't make it into the
+	// committed block: "evict" them, or "requeue" them for the next height
+	UncommittedBundlePolicy string `mapstructure:"uncommitted_bundle_policy"`
+	// Maximum number of txs held across all incomplete bundles, past which the
+	// least recently progressed incomplete bundle is evicted (0 - unlimited)
+	MaxBufferedOrders int `mapstructure:"max_buffered_orders"`
+	// Comma separated list of peer ids that are allowed to submit bundles
+	// (empty - any sidecar peer)
+	AllowlistPeerIDs string `mapstructure:"allowlist_peer_ids"`
+	// Accept Updates for a height lower than the last one the sidecar was
+	// updated to (e.g. on a rollback), instead of rejecting them
+	AllowReorgUpdates bool `mapstructure:"allow_reorg_updates"`
+	// How long ReapMaxTxsWithDeadline waits for incomplete bundles at the
+	// auction height to fill up before reaping (0 - don't wait)
+	ReapGracePeriod time.Duration `mapstructure:"reap_grace_period"`
+	// Run CheckTx on every bundle tx, dropping the whole bundle if the app
+	// rejects one of its txs
+	CheckTxs bool `mapstructure:"check_txs"`
+	// Value of the "sidecar" label on this sidecar's metrics, to tell apart
+	// several sidecars reporting to the same registry
+	MetricsLabel string `mapstructure:"metrics_label"`
+	// How long after a height is committed a bundle for it that's only missing
+	// its final order still accepts that order, to be reaped at the next
+	// height (0 - never)
+	LateOrderGrace time.Duration `mapstructure:"late_order_grace"`
+	// What to do with orders for the auction height that arrive after the
+	// first reap for it: treat them as "eligible" for later reaps, or "reject"
+	// them
+	CurrentHeightPolicy string `mapstructure:"current_height_policy"`
+	// Index bundles by the peer that sent them as well as by their id, so
+	// peers using the same bundle id don't clobber each other's bundles
+	NamespaceBundlesBySender bool `mapstructure:"namespace_bundles_by_sender"`
+	// Maximum number of a bundle's txs validated at once when CheckTxs is set,
+	// and the bundle is added whole (0 - one at a time)
+	CheckTxWorkers int `mapstructure:"check_tx_workers"`
+	// Total size of the sidecar's txs, in bytes, past which incomplete bundles
+	// are evicted, least recently progressed first, until back under it
+	// (0 - unlimited)
+	SoftMaxTxsBytes int64 `mapstructure:"soft_max_txs_bytes"`
+	// Maximum number of bundles added whole validated with CheckTx at once
+	// (0 - unlimited)
+	MaxConcurrentBundleChecks int `mapstructure:"max_concurrent_bundle_checks"`
+	// What to do with bundles over MaxConcurrentBundleChecks: queue them
+	// until a validation finishes, or reject them
+	BundleChecksOverflowPolicy string `mapstructure:"bundle_checks_overflow_policy"`
+	// Whether incomplete bundles with pinned txs are kept or evicted when over
+	// MaxBufferedOrders or SoftMaxTxsBytes
+	PinnedBundlePolicy string `mapstructure:"pinned_bundle_policy"`
+	// Maximum size of the bundles reaped for a block, in bytes, whatever
+	// budget the reap is given (0 - unlimited)
+	MaxReapBytesPerHeight int64 `mapstructure:"max_reap_bytes_per_height"`
+	// Fractions of the block's MaxBytes and MaxGas consensus params the
+	// bundles reaped for it may take, between 0 and 1 (0 - the whole block)
+	ReapBytesFraction float64 `mapstructure:"reap_bytes_fraction"`
+	ReapGasFraction   float64 `mapstructure:"reap_gas_fraction"`
+	// Maximum number of txs in the sidecar, further txs are rejected
+	// (0 - unlimited)
+	MaxSidecarTxs int `mapstructure:"max_sidecar_txs"`
+	// Maximum number of bundles a single reap returns, whatever budget is
+	// left (0 - unlimited)
+	MaxBundlesPerReap int `mapstructure:"max_bundles_per_reap"`
+	// Evict a bundle, counting it against the peer, as soon as an order is
+	// inconsistent with it or it's still missing orders when its height is
+	// reaped, instead of only dropping the offending order
+	StrictMode bool `mapstructure:"strict_mode"`
+	// Fill what's left of a reap's budget, once complete bundles are reaped,
+	// with contiguous prefixes of incomplete bundles
+	AllowPartialBundles bool `mapstructure:"allow_partial_bundles"`
+	// Index of the first order of a bundle, as sent by searchers: 0 or 1
+	BundleOrderBase int `mapstructure:"bundle_order_base"`
+	// Directory accepted bundles are appended to, for post-mortem analysis
+	// (empty - disabled)
+	BundleExportPath string `mapstructure:"bundle_export_path"`
+	// Size in bytes past which the bundle export file is rotated (0 - never)
+	BundleExportMaxFileSize int64 `mapstructure:"bundle_export_max_file_size"`
+	// Number of reaps in a row a complete bundle can be skipped for not
+	// fitting in the budget before it's starved (0 - never)
+	StarvedBundleThreshold int `mapstructure:"starved_bundle_threshold"`
+	// What to do with a starved bundle: "evict" it, or "prioritize" it
+	StarvedBundlePolicy string `mapstructure:"starved_bundle_policy"`
+	// Let ForceComplete fill the missing orders of stuck bundles, for recovery
+	// tooling. Never set in production
+	AllowForceComplete bool `mapstructure:"allow_force_complete"`
+	// When the sidecar's config is updated at runtime, evict incomplete
+	// bundles until back within the new MaxBufferedOrders and SoftMaxTxsBytes,
+	// instead of only applying them to txs added from then on
+	EvictOverLimitsOnConfigUpdate bool `mapstructure:"evict_over_limits_on_config_update"`
+	// Acknowledge every bundle a sidecar peer forwards once all its orders
+	// arrived, and pass on the acks of bundles forwarded to peers
+	AckBundles bool `mapstructure:"ack_bundles"`
 }
 
 func DefaultSidecarConfig() *SidecarConfig {
 	return &SidecarConfig{
-		RelayerID:       "",
-		PersonalPeerIDs: "",
+		RelayerID:                  "",
+		PersonalPeerIDs:            "",
+		UncommittedBundlePolicy:    SidecarBundlePolicyEvict,
+		MaxBufferedOrders:          0,
+		CurrentHeightPolicy:        SidecarCurrentHeightEligible,
+		CheckTxWorkers:             4,
+		BundleChecksOverflowPolicy: SidecarBundleChecksQueue,
+		PinnedBundlePolicy:         SidecarPinnedBundleKeep,
+		BundleExportPath:           "",
+		BundleExportMaxFileSize:    10 * 1024 * 1024, // 10MB
+		StarvedBundlePolicy:        SidecarStarvedBundleEvict,
 	}
 }
 
 func TestSidecarConfig() *SidecarConfig {
 	return &SidecarConfig{
-		RelayerID:       "",
-		PersonalPeerIDs: "",
+		RelayerID:                  "",
+		PersonalPeerIDs:            "",
+		UncommittedBundlePolicy:    SidecarBundlePolicyEvict,
+		MaxBufferedOrders:          0,
+		CurrentHeightPolicy:        SidecarCurrentHeightEligible,
+		CheckTxWorkers:             4,
+		BundleChecksOverflowPolicy: SidecarBundleChecksQueue,
+		PinnedBundlePolicy:         SidecarPinnedBundleKeep,
+		BundleExportPath:           "",
+		BundleExportMaxFileSize:    10 * 1024 * 1024, // 10MB
+		StarvedBundlePolicy:        SidecarStarvedBundleEvict,
 	}
 }
 
-// no-op validation
+// BundleExportDir returns the full path to the directory bundles are exported to
+func (s *SidecarConfig) BundleExportDir() string {
+	return rootify(s.BundleExportPath, s.RootDir)
+}
+
+// BundleExportEnabled returns true if bundles are exported.
+func (s *SidecarConfig) BundleExportEnabled() bool {
+	return s.BundleExportPath != ""
+}
+
+// ValidateBasic performs basic validation (checking param bounds, etc.) and
+// returns an error if any check fails.
 func (s *SidecarConfig) ValidateBasic() error {
+	switch s.UncommittedBundlePolicy {
+	case SidecarBundlePolicyEvict, SidecarBundlePolicyRequeue:
+	default:
+		return fmt.Errorf("unknown uncommitted_bundle_policy %s", s.UncommittedBundlePolicy)
+	}
+	switch s.CurrentHeightPolicy {
+	case SidecarCurrentHeightEligible, SidecarCurrentHeightReject:
+	default:
+		return fmt.Errorf("unknown current_height_policy %s", s.CurrentHeightPolicy)
+	}
+	switch s.BundleChecksOverflowPolicy {
+	case SidecarBundleChecksQueue, SidecarBundleChecksReject:
+	default:
+		return fmt.Errorf("unknown bundle_checks_overflow_policy %s", s.BundleChecksOverflowPolicy)
+	}
+	switch s.PinnedBundlePolicy {
+	case SidecarPinnedBundleKeep, SidecarPinnedBundleEvict:
+	default:
+		return fmt.Errorf("unknown pinned_bundle_policy %s", s.PinnedBundlePolicy)
+	}
+	switch s.StarvedBundlePolicy {
+	case SidecarStarvedBundleEvict, SidecarStarvedBundlePrioritize:
+	default:
+		return fmt.Errorf("unknown starved_bundle_policy %s", s.StarvedBundlePolicy)
+	}
+	if s.MaxBufferedOrders < 0 {
+		return errors.New("max_buffered_orders can't be negative")
+	}
+	if s.CheckTxWorkers < 0 {
+		return errors.New("check_tx_workers can't be negative")
+	}
+	if s.SoftMaxTxsBytes < 0 {
+		return errors.New("soft_max_txs_bytes can't be negative")
+	}
+	if s.MaxConcurrentBundleChecks < 0 {
+		return errors.New("max_concurrent_bundle_checks can't be negative")
+	}
+	if s.MaxReapBytesPerHeight < 0 {
+		return errors.New("max_reap_bytes_per_height can't be negative")
+	}
+	if s.ReapBytesFraction < 0 || s.ReapBytesFraction > 1 {
+		return errors.New("reap_bytes_fraction must be between 0 and 1")
+	}
+	if s.ReapGasFraction < 0 || s.ReapGasFraction > 1 {
+		return errors.New("reap_gas_fraction must be between 0 and 1")
+	}
+	if s.MaxSidecarTxs < 0 {
+		return errors.New("max_sidecar_txs can't be negative")
+	}
+	if s.MaxBundlesPerReap < 0 {
+		return errors.New("max_bundles_per_reap can't be negative")
+	}
+	if s.BundleOrderBase != 0 && s.BundleOrderBase != 1 {
+		return errors.New("bundle_order_base must be 0 or 1")
+	}
+	if s.BundleExportMaxFileSize < 0 {
+		return errors.New("bundle_export_max_file_size can't be negative")
+	}
+	if s.StarvedBundleThreshold < 0 {
+		return errors.New("starved_bundle_threshold can't be negative")
+	}
+	if s.ReapGracePeriod < 0 {
+		return errors.New("reap_grace_period can't be negative")
+	}
+	if s.LateOrderGrace < 0 {
+		return errors.New("late_order_grace can't be negative")
+	}
+	return nil
+}
+
+// Validate performs basic validation, like ValidateBasic, and also checks that
+// limits don't contradict each other, which would leave a sidecar that rejects
+// everything or silently ignores a setting.
+func (s *SidecarConfig) Validate() error {
+	if err := s.ValidateBasic(); err != nil {
+		return err
+	}
+	if s.MaxSidecarTxs > 0 && s.MaxBufferedOrders > s.MaxSidecarTxs {
+		return fmt.Errorf("max_buffered_orders (%d) can't be more than max_sidecar_txs (%d), which would reject orders first",
+			s.MaxBufferedOrders, s.MaxSidecarTxs)
+	}
+	if s.StrictMode && s.AllowPartialBundles {
+		return errors.New("allow_partial_bundles can't be set with strict_mode, which evicts incomplete bundles instead")
+	}
 	return nil
 }
 
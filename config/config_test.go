@@ -141,6 +141,137 @@ func TestFastSyncConfigValidateBasic(t *testing.T) {
 	assert.Error(t, cfg.ValidateBasic())
 }
 
+func TestSidecarConfigValidateBasic(t *testing.T) {
+	cfg := TestSidecarConfig()
+	assert.NoError(t, cfg.ValidateBasic())
+
+	// tamper with uncommitted bundle policy
+	cfg.UncommittedBundlePolicy = SidecarBundlePolicyRequeue
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.UncommittedBundlePolicy = "invalid"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.UncommittedBundlePolicy = SidecarBundlePolicyEvict
+
+	cfg.MaxBufferedOrders = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MaxBufferedOrders = 0
+
+	cfg.CheckTxWorkers = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.CheckTxWorkers = 0
+
+	cfg.SoftMaxTxsBytes = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.SoftMaxTxsBytes = 0
+
+	cfg.MaxConcurrentBundleChecks = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MaxConcurrentBundleChecks = 0
+
+	cfg.MaxReapBytesPerHeight = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MaxReapBytesPerHeight = 0
+
+	cfg.ReapBytesFraction = -0.5
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.ReapBytesFraction = 1.5
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.ReapBytesFraction = 0.5
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.ReapBytesFraction = 0
+
+	cfg.ReapGasFraction = -0.5
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.ReapGasFraction = 1.5
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.ReapGasFraction = 0
+
+	cfg.MaxSidecarTxs = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MaxSidecarTxs = 0
+
+	cfg.MaxBundlesPerReap = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MaxBundlesPerReap = 0
+
+	cfg.BundleOrderBase = 1
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.BundleOrderBase = 2
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.BundleOrderBase = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.BundleOrderBase = 0
+
+	cfg.BundleExportMaxFileSize = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.BundleExportMaxFileSize = 0
+
+	cfg.StarvedBundleThreshold = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.StarvedBundleThreshold = 0
+
+	cfg.StarvedBundlePolicy = SidecarStarvedBundlePrioritize
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.StarvedBundlePolicy = "starve"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.StarvedBundlePolicy = SidecarStarvedBundleEvict
+
+	cfg.ReapGracePeriod = -time.Second
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.ReapGracePeriod = 0
+
+	cfg.LateOrderGrace = -time.Second
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.LateOrderGrace = 0
+
+	cfg.CurrentHeightPolicy = SidecarCurrentHeightReject
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.CurrentHeightPolicy = "invalid"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.CurrentHeightPolicy = SidecarCurrentHeightEligible
+
+	cfg.BundleChecksOverflowPolicy = SidecarBundleChecksReject
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.BundleChecksOverflowPolicy = "invalid"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.BundleChecksOverflowPolicy = SidecarBundleChecksQueue
+
+	cfg.PinnedBundlePolicy = SidecarPinnedBundleEvict
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.PinnedBundlePolicy = "invalid"
+	assert.Error(t, cfg.ValidateBasic())
+}
+
+func TestSidecarConfigValidate(t *testing.T) {
+	cfg := TestSidecarConfig()
+	assert.NoError(t, cfg.Validate())
+
+	// basic validation is included
+	cfg.MaxBufferedOrders = -1
+	assert.Error(t, cfg.Validate())
+
+	cfg.MaxSidecarTxs = 10
+	cfg.MaxBufferedOrders = 10
+	assert.NoError(t, cfg.Validate())
+	cfg.MaxBufferedOrders = 11
+	assert.EqualError(t, cfg.Validate(),
+		"max_buffered_orders (11) can't be more than max_sidecar_txs (10), which would reject orders first")
+	cfg.MaxSidecarTxs = 0
+	assert.NoError(t, cfg.Validate())
+	cfg.MaxBufferedOrders = 0
+
+	cfg.StrictMode = true
+	assert.NoError(t, cfg.Validate())
+	cfg.AllowPartialBundles = true
+	assert.Error(t, cfg.Validate())
+	cfg.StrictMode = false
+	assert.NoError(t, cfg.Validate())
+}
+
 func TestConsensusConfig_ValidateBasic(t *testing.T) {
 	// nolint: lll
 	testcases := map[string]struct {
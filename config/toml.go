@@ -500,6 +500,7 @@ namespace = "{{ .Instrumentation.Namespace }}"
 #######################################################
 ###       Sidecar Configuration Options          ###
 #######################################################
+[sidecar]
 
 # comma separated list of peer ids that represent the nodes
 # you run that this node is aware of / can communicate with
@@ -508,6 +509,184 @@ namespace = "{{ .Instrumentation.Namespace }}"
 # txs when when your validator is the proposer)
 personal_peer_ids = "{{ .Sidecar.PersonalPeerIDs }}"
 relayer_id = "{{ .Sidecar.RelayerID }}"
+
+# What to do with the txs of a bundle this node reaped for a proposal, but
+# that didn't make it into the committed block:
+#   1) "evict" (default) - drop them along with the rest of the height's bundles
+#   2) "requeue" - keep them, in order, as a bundle for the next height
+uncommitted_bundle_policy = "{{ .Sidecar.UncommittedBundlePolicy }}"
+
+# Maximum number of txs buffered across all bundles that are still waiting on
+# orders. Once exceeded, the incomplete bundle that least recently received an
+# order is evicted.
+# 0 - unlimited.
+max_buffered_orders = {{ .Sidecar.MaxBufferedOrders }}
+
+# comma separated list of peer ids that this node accepts bundles from
+# (e.g. known searchers or relayers). Bundles from any other peer are dropped.
+# Leave empty to accept bundles from any sidecar peer.
+allowlist_peer_ids = "{{ .Sidecar.AllowlistPeerIDs }}"
+
+# Set to true to let the sidecar be updated to a height lower than the last
+# committed one (e.g. after rolling back the chain). By default, such updates
+# are rejected.
+allow_reorg_updates = {{ .Sidecar.AllowReorgUpdates }}
+
+# How long the proposer waits, when reaping with a deadline, for bundles at the
+# auction height that are still missing orders. Reaping happens as soon as
+# every bundle is complete or the grace period elapses, whichever is first.
+# 0 - don't wait.
+reap_grace_period = "{{ .Sidecar.ReapGracePeriod }}"
+
+# Set to true to have the app validate every bundle tx with CheckTx before it's
+# added to the sidecar. If the app rejects a tx, its whole bundle is dropped.
+check_txs = {{ .Sidecar.CheckTxs }}
+
+# Value of the "sidecar" label on this sidecar's metrics. Set it to tell apart
+# the metrics of several sidecars reporting to the same Prometheus registry.
+metrics_label = "{{ .Sidecar.MetricsLabel }}"
+
+# How long after a height is committed a bundle for that height that is only
+# missing its final order (e.g. because of network jitter) still accepts it.
+# Once completed, the bundle is reaped at the next height.
+# 0 - late orders are rejected.
+late_order_grace = "{{ .Sidecar.LateOrderGrace }}"
+
+# What to do with orders for the auction height that arrive once the proposer
+# started reaping that height:
+#   1) "eligible" (default) - accept them, to be included by later reaps
+#   2) "reject" - reject them, the height is closed to new orders
+current_height_policy = "{{ .Sidecar.CurrentHeightPolicy }}"
+
+# Set to true to keep the bundles of different peers apart, even if they use
+# the same bundle id. Only enable it on nodes receiving bundles straight from
+# searchers: the orders of a bundle gossiped along several paths would be
+# split apart.
+namespace_bundles_by_sender = {{ .Sidecar.NamespaceBundlesBySender }}
+
+# Maximum number of txs of a bundle validated with CheckTx at the same time,
+# when check_txs is set and the bundle is submitted whole. All of a bundle's
+# txs are validated before any is added.
+# 0 - one at a time.
+check_tx_workers = {{ .Sidecar.CheckTxWorkers }}
+
+# Soft limit on the total size of the sidecar's txs, in bytes, e.g. to keep
+# memory usage in check. Once crossed, bundles that are still waiting on orders
+# are evicted, starting with the one that least recently received an order,
+# until back under the limit. Complete bundles are never evicted.
+# 0 - unlimited.
+soft_max_txs_bytes = {{ .Sidecar.SoftMaxTxsBytes }}
+
+# Hard limit on the number of txs in the sidecar, whatever their size. Once
+# reached, new txs are rejected, even orders that would complete a bundle,
+# until txs are committed or evicted.
+# 0 - unlimited.
+max_sidecar_txs = {{ .Sidecar.MaxSidecarTxs }}
+
+# Maximum number of bundles validated with CheckTx at the same time, when
+# check_txs is set and bundles are submitted whole, so many bundles completing
+# at once don't overwhelm the app connection.
+# 0 - unlimited.
+max_concurrent_bundle_checks = {{ .Sidecar.MaxConcurrentBundleChecks }}
+
+# What to do with a bundle submitted while max_concurrent_bundle_checks
+# bundles are being validated:
+#   1) "queue" (default) - wait for one of them to finish
+#   2) "reject" - reject the bundle, to be resubmitted later
+bundle_checks_overflow_policy = "{{ .Sidecar.BundleChecksOverflowPolicy }}"
+
+# What to do with bundles still waiting on orders that have a pinned tx, a
+# must-include anchor of the bundle, when over max_buffered_orders or
+# soft_max_txs_bytes:
+#   1) "keep" (default) - never evict them, even if that keeps the sidecar over
+#   the limit
+#   2) "evict" - evict them like any other bundle, counting them separately
+pinned_bundle_policy = "{{ .Sidecar.PinnedBundlePolicy }}"
+
+# Hard limit on the total size of the bundles reaped for a single block, in
+# bytes, separate from the sidecar's capacity. Applies on top of the block's
+# own byte budget, the smaller of the two wins.
+# 0 - unlimited.
+max_reap_bytes_per_height = {{ .Sidecar.MaxReapBytesPerHeight }}
+
+# Fractions of the block's max_bytes and max_gas consensus params that the
+# bundles reaped for it may take, between 0 and 1. Resolved against the
+# consensus params at each reap, so they follow param changes.
+# 0 - the whole block.
+reap_bytes_fraction = {{ .Sidecar.ReapBytesFraction }}
+reap_gas_fraction = {{ .Sidecar.ReapGasFraction }}
+
+# Maximum number of bundles reaped for a single block, whatever is left of its
+# byte and gas budget, to keep block composition predictable. Bundles past the
+# cap aren't reaped, and are carried over to the next height if their max
+# height allows it.
+# 0 - unlimited.
+max_bundles_per_reap = {{ .Sidecar.MaxBundlesPerReap }}
+
+# Don't tolerate malformed bundles: a bundle is evicted as soon as one of its
+# orders is inconsistent with it (another size, an order past its size,
+# another tx at a taken order, or a past height), or if it's still missing
+# orders when its height is reaped. Each such eviction is counted against the
+# peer that sent the offending order. Best used with
+# namespace_bundles_by_sender, so peers can only affect their own bundles.
+strict_mode = {{ .Sidecar.StrictMode }}
+
+# Once every complete bundle that fits is reaped, fill what's left of the
+# reap's byte and gas budget with partial bundles: the txs of an incomplete
+# bundle from order 0 up to its first missing order, as many as fit. Partial
+# bundles aren't atomic, so only enable this if searchers accept their bundles
+# being cut. They don't count as reaped bundles, e.g. for
+# max_bundles_per_reap, and are never requeued. Bundles pending admission,
+# and bundles evicted in strict mode, are left out. A bundle whose first
+# order sets a minimum number of orders to reap is left out until at least
+# that many of its orders can be taken.
+allow_partial_bundles = {{ .Sidecar.AllowPartialBundles }}
+
+# Index of the first order of a bundle, as sent in bundle txs: 0 or 1. With
+# 1, orders 1 to size make up a bundle. The sidecar numbers orders from 0
+# internally, which is what its errors and APIs report.
+bundle_order_base = {{ .Sidecar.BundleOrderBase }}
+
+# Directory every accepted bundle is appended to, for post-mortem analysis of
+# what the sidecar saw. Each order is written as the length-delimited MEVMessage
+# it's gossiped as, in bundle order. Relative paths are relative to the home
+# directory. Empty disables the export.
+bundle_export_path = "{{ js .Sidecar.BundleExportPath }}"
+
+# Size in bytes past which the export file is rotated, as bundles.000,
+# bundles.001 and so on, next to it. Bundles are never split across files.
+# 0 - never rotate.
+bundle_export_max_file_size = {{ .Sidecar.BundleExportMaxFileSize }}
+
+# Number of reaps in a row a complete bundle can be skipped for not fitting in
+# what's left of the reap's byte and gas budget before it's considered
+# starved, and starved_bundle_policy applies to it. Being reaped starts the
+# count over.
+# 0 - bundles never starve.
+starved_bundle_threshold = {{ .Sidecar.StarvedBundleThreshold }}
+
+# What to do with a starved bundle:
+# - "evict": evict it, counting it against its peer like any other eviction
+# - "prioritize": reap it ahead of every other bundle from then on, so it gets
+#   first pick of the budget. ReapPage still returns it in its usual place.
+starved_bundle_policy = "{{ .Sidecar.StarvedBundlePolicy }}"
+
+# Let recovery tooling fill the missing orders of a stuck bundle with txs of
+# its own, so the bundle can be reaped. Those txs never came from the
+# bundle's searcher: never set this in production.
+allow_force_complete = {{ .Sidecar.AllowForceComplete }}
+
+# When the sidecar's config is updated at runtime, evict incomplete bundles,
+# least recently progressed first, until back within the new
+# max_buffered_orders and soft_max_txs_bytes. Otherwise tightened limits only
+# apply to txs added from then on.
+evict_over_limits_on_config_update = {{ .Sidecar.EvictOverLimitsOnConfigUpdate }}
+
+# Acknowledge every bundle a sidecar peer forwards, once all of its orders
+# arrived, so the peer can confirm it propagated. Acks received for bundles
+# forwarded to peers are passed on to the mempool reactor's bundle ack hook.
+# Each ack is an extra message per bundle and peer.
+ack_bundles = {{ .Sidecar.AckBundles }}
 `
 
 /****** these are for test settings ***********/
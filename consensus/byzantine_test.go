@@ -70,7 +70,7 @@ func TestByzantinePrevoteEquivocation(t *testing.T) {
 			mempool.EnableTxsAvailable()
 		}
 
-		sidecar := mempl.NewCListSidecar(0)
+		sidecar := mempl.NewCListSidecar(thisConfig.Sidecar, 0)
 		// Make a full instance of the evidence pool
 		evidenceDB := dbm.NewMemDB()
 		evpool, err := evidence.NewPool(evidenceDB, stateStore, blockStore)
@@ -400,7 +400,7 @@ func newStateWithConfigAndBlockStore(
 		mempool.EnableTxsAvailable()
 	}
 
-	sidecar := mempl.NewCListSidecar(0)
+	sidecar := mempl.NewCListSidecar(thisConfig.Sidecar, 0)
 	evpool := sm.EmptyEvidencePool{}
 
 	// Make State
@@ -48,6 +48,9 @@ type Reactor struct {
 	eventBus *types.EventBus
 	rs       *cstypes.RoundState
 
+	// if set, called once the node is done syncing, see ReactorOnSwitchToConsensus
+	onSwitchToConsensus func()
+
 	Metrics *Metrics
 }
 
@@ -123,6 +126,9 @@ func (conR *Reactor) SwitchToConsensus(state sm.State, skipWAL bool) {
 	conR.mtx.Unlock()
 	conR.Metrics.FastSyncing.Set(0)
 	conR.Metrics.StateSyncing.Set(0)
+	if conR.onSwitchToConsensus != nil {
+		conR.onSwitchToConsensus()
+	}
 
 	if skipWAL {
 		conR.conS.doWALCatchup = false
@@ -937,6 +943,12 @@ func ReactorMetrics(metrics *Metrics) ReactorOption {
 	return func(conR *Reactor) { conR.Metrics = metrics }
 }
 
+// ReactorOnSwitchToConsensus sets a function called when the reactor switches
+// to consensus, once the node is done syncing
+func ReactorOnSwitchToConsensus(fn func()) ReactorOption {
+	return func(conR *Reactor) { conR.onSwitchToConsensus = fn }
+}
+
 //-----------------------------------------------------------------------------
 
 var (
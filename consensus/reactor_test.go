@@ -164,7 +164,7 @@ func TestReactorWithEvidence(t *testing.T) {
 			mempool.EnableTxsAvailable()
 		}
 
-		sidecar := mempl.NewCListSidecar(0)
+		sidecar := mempl.NewCListSidecar(thisConfig.Sidecar, 0)
 		// mock the evidence pool
 		// everyone includes evidence of another double signing
 		vIdx := (i + 1) % nValidators
@@ -74,6 +74,9 @@ func (emptySidecar) EnableTxsAvailable()           {}
 
 func (emptySidecar) Size() int       { return 0 }
 func (emptySidecar) TxsBytes() int64 { return 0 }
+func (emptySidecar) NumBundles() int { return 0 }
+
+func (emptySidecar) HasTx(_ [mempl.TxKeySize]byte) bool { return false }
 
 //-----------------------------------------------------------------------------
 // mockProxyApp uses ABCIResponses to give the right results.
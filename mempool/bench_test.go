@@ -3,9 +3,12 @@ package mempool
 import (
 	"encoding/binary"
 	"testing"
+	"time"
 
 	"github.com/tendermint/tendermint/abci/example/kvstore"
+	cfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/proxy"
+	"github.com/tendermint/tendermint/types"
 )
 
 func BenchmarkReap(b *testing.B) {
@@ -71,3 +74,53 @@ func BenchmarkCacheRemoveTime(b *testing.B) {
 		cache.Remove(txs[i])
 	}
 }
+
+func BenchmarkSidecarReapMaxTxs(b *testing.B) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	addNumBundlesToSidecar(nil, sidecar, 100, 10, UnknownPeerID)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sidecar.ReapMaxTxs()
+	}
+}
+
+func BenchmarkSidecarReapMaxTxsInto(b *testing.B) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	addNumBundlesToSidecar(nil, sidecar, 100, 10, UnknownPeerID)
+	buf := make([]*MempoolTx, 0, sidecar.Size())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = sidecar.ReapMaxTxsInto(buf)
+	}
+}
+
+func BenchmarkSidecarAddBundleCheckTxSerial(b *testing.B) {
+	benchmarkSidecarAddBundleCheckTx(b, 1)
+}
+
+func BenchmarkSidecarAddBundleCheckTxPooled(b *testing.B) {
+	benchmarkSidecarAddBundleCheckTx(b, 8)
+}
+
+// benchmarkSidecarAddBundleCheckTx adds bundles of 16 txs, against an app
+// taking 100µs per CheckTx
+func benchmarkSidecarAddBundleCheckTx(b *testing.B, workers int) {
+	config := cfg.TestSidecarConfig()
+	config.CheckTxWorkers = workers
+	sidecar := NewCListSidecar(config, 0, WithSidecarProxyAppConn(&slowAppConnMempool{delay: 100 * time.Microsecond}))
+
+	bundles := make([]types.Txs, b.N)
+	for i := range bundles {
+		bundles[i] = randomTxs(16)
+	}
+	b.ResetTimer()
+	for i, txs := range bundles {
+		if _, err := sidecar.AddBundle(txs, TxInfo{DesiredHeight: 1, BundleId: int64(i)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
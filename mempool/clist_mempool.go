@@ -71,6 +71,9 @@ type CListMempool struct {
 	logger log.Logger
 
 	metrics *Metrics
+
+	// bundle txs are routed to it by CheckTx, see WithSidecar
+	sidecar PriorityTxSidecar
 }
 
 var _ Mempool = &CListMempool{}
@@ -136,6 +139,13 @@ func WithMetrics(metrics *Metrics) CListMempoolOption {
 	return func(mem *CListMempool) { mem.metrics = metrics }
 }
 
+// WithSidecar has CheckTx route bundle txs, the ones with a TxInfo.BundleSize,
+// to sidecar, e.g. for bundles gossiped over the MempoolChannel. Without a
+// sidecar, bundle txs are rejected.
+func WithSidecar(sidecar PriorityTxSidecar) CListMempoolOption {
+	return func(mem *CListMempool) { mem.sidecar = sidecar }
+}
+
 func (mem *CListMempool) InitWAL() error {
 	var (
 		walDir  = mem.config.WalDir()
@@ -183,6 +193,32 @@ func (mem *CListMempool) TxsBytes() int64 {
 	return atomic.LoadInt64(&mem.txsBytes)
 }
 
+// CombinedStats returns the sizes of the mempool and of its sidecar, if any,
+// read together, so they can't straddle a block being committed. The sidecar
+// stats are zero without a sidecar.
+//
+// Safe for concurrent use by multiple goroutines, it holds the mempool's read
+// lock and the sidecar's lock while it runs.
+func (mem *CListMempool) CombinedStats() CombinedStats {
+	// same lock order as block execution: the mempool's, then the sidecar's
+	mem.updateMtx.RLock()
+	defer mem.updateMtx.RUnlock()
+
+	stats := CombinedStats{
+		MempoolTxs:   mem.Size(),
+		MempoolBytes: mem.TxsBytes(),
+	}
+	if mem.sidecar == nil {
+		return stats
+	}
+	mem.sidecar.Lock()
+	defer mem.sidecar.Unlock()
+	stats.SidecarTxs = mem.sidecar.Size()
+	stats.SidecarBytes = mem.sidecar.TxsBytes()
+	stats.SidecarBundles = mem.sidecar.NumBundles()
+	return stats
+}
+
 // Lock() must be help by the caller during execution.
 func (mem *CListMempool) FlushAppConn() error {
 	return mem.proxyAppConn.FlushSync()
@@ -228,10 +264,20 @@ func (mem *CListMempool) TxsWaitChan() <-chan struct{} {
 // It blocks if we're waiting on Update() or Reap().
 // cb: A callback from the CheckTx command.
 //     It gets called from another goroutine.
-// CONTRACT: Either cb will get called, or err returned.
+// CONTRACT: Either cb will get called, or err returned. Bundle txs are the
+// exception: they're added to the sidecar, see WithSidecar, and cb is never
+// called.
 //
 // Safe for concurrent use by multiple goroutines.
 func (mem *CListMempool) CheckTx(tx types.Tx, cb func(*abci.Response), txInfo TxInfo) error {
+	// bundle txs are orders for the sidecar, not the mempool
+	if txInfo.BundleSize > 0 {
+		if mem.sidecar == nil {
+			return ErrNoSidecarForBundleTx
+		}
+		return mem.sidecar.AddTx(tx, txInfo)
+	}
+
 	mem.updateMtx.RLock()
 	// use defer to unlock mutex because application (*local client*) might panic
 	defer mem.updateMtx.RUnlock()
@@ -369,13 +415,19 @@ func (mem *CListMempool) removeTx(tx types.Tx, elem *clist.CElement, removeFromC
 }
 
 // RemoveTxByKey removes a transaction from the mempool by its TxKey index.
-func (mem *CListMempool) RemoveTxByKey(txKey [TxKeySize]byte, removeFromCache bool) {
+// Txs the sidecar, if any, holds in a bundle aren't removed, and
+// ErrTxInBundle is returned, as a bundle is only valid whole.
+func (mem *CListMempool) RemoveTxByKey(txKey [TxKeySize]byte, removeFromCache bool) error {
+	if mem.sidecar != nil && mem.sidecar.HasTx(txKey) {
+		return ErrTxInBundle
+	}
 	if e, ok := mem.txsMap.Load(txKey); ok {
 		memTx := e.(*clist.CElement).Value.(*MempoolTx)
 		if memTx != nil {
 			mem.removeTx(memTx.tx, e.(*clist.CElement), removeFromCache)
 		}
 	}
+	return nil
 }
 
 func (mem *CListMempool) isFull(txSize int) error {
@@ -424,6 +476,8 @@ func (mem *CListMempool) resCbFirstTime(
 				height:    mem.height,
 				gasWanted: r.CheckTx.GasWanted,
 				tx:        tx,
+				source:    SourceMempool,
+				senders:   &sync.Map{},
 			}
 			memTx.senders.Store(peerID, true)
 			mem.addTx(memTx)
@@ -581,6 +635,52 @@ func (mem *CListMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64, sidecarTxs [
 	return txs
 }
 
+// VerifyReapFits returns an ErrReapTooLarge if txs take more than maxBytes
+// bytes (as proto encoded in a block) or maxGas gas, guarding a proposal
+// against a reap that went over the block's limits. A negative max means that
+// resource is unlimited, like for ReapMaxBytesMaxGas.
+func VerifyReapFits(txs []*MempoolTx, maxBytes, maxGas int64) error {
+	var totalBytes, totalGas int64
+	for _, memTx := range txs {
+		totalBytes += types.ComputeProtoSizeForTxs([]types.Tx{memTx.tx})
+		totalGas += memTx.gasWanted
+	}
+	if maxBytes > -1 && totalBytes > maxBytes {
+		return ErrReapTooLarge{"bytes", maxBytes, totalBytes}
+	}
+	if maxGas > -1 && totalGas > maxGas {
+		return ErrReapTooLarge{"gas", maxGas, totalGas}
+	}
+	return nil
+}
+
+// DiffReap returns the keys of the txs only reap a has, and of those only
+// reap b has, each in the order of its reap, e.g. to find where a proposer
+// and a validator disagree. A tx reaped more times by one reap than the
+// other is reported as many more times. Txs both reaps have in a different
+// order aren't reported.
+func DiffReap(a, b []*MempoolTx) (onlyA, onlyB [][TxKeySize]byte) {
+	return reapOnlyIn(a, b), reapOnlyIn(b, a)
+}
+
+// reapOnlyIn returns the keys of the txs of reap that other doesn't have.
+func reapOnlyIn(reap, other []*MempoolTx) [][TxKeySize]byte {
+	counts := make(map[[TxKeySize]byte]int, len(other))
+	for _, memTx := range other {
+		counts[TxKey(memTx.tx)]++
+	}
+	only := make([][TxKeySize]byte, 0)
+	for _, memTx := range reap {
+		key := TxKey(memTx.tx)
+		if counts[key] > 0 {
+			counts[key]--
+			continue
+		}
+		only = append(only, key)
+	}
+	return only
+}
+
 // Safe for concurrent use by multiple goroutines.
 func (mem *CListMempool) ReapMaxTxs(max int) types.Txs {
 	mem.updateMtx.RLock()
@@ -689,6 +789,17 @@ func (memTx *MempoolTx) Height() int64 {
 	return atomic.LoadInt64(&memTx.height)
 }
 
+// Source returns where this transaction was ingested from
+func (memTx *MempoolTx) Source() TxSource {
+	return memTx.source
+}
+
+// ExecHint returns the execution ordering hint the transaction was submitted
+// with to the sidecar, see TxInfo.ExecHint
+func (memTx *MempoolTx) ExecHint() int64 {
+	return memTx.execHint
+}
+
 //--------------------------------------------------------------------------------
 
 type txCache interface {
@@ -57,7 +57,7 @@ func newMempoolWithAppAndConfig(cc proxy.ClientCreator, config *cfg.Config) (*CL
 		panic(err)
 	}
 	mempool := NewCListMempool(config.Mempool, appConnMem, 0)
-	sidecar := NewCListSidecar(0)
+	sidecar := NewCListSidecar(config.Sidecar, 0)
 	mempool.SetLogger(log.TestingLogger())
 	return mempool, sidecar, func() { os.RemoveAll(config.RootDir) }
 }
@@ -1182,13 +1182,126 @@ func TestMempoolTxsBytes(t *testing.T) {
 	err = mempool.CheckTx([]byte{0x06}, nil, TxInfo{})
 	require.NoError(t, err)
 	assert.EqualValues(t, 1, mempool.TxsBytes())
-	mempool.RemoveTxByKey(TxKey([]byte{0x07}), true)
+	require.NoError(t, mempool.RemoveTxByKey(TxKey([]byte{0x07}), true))
 	assert.EqualValues(t, 1, mempool.TxsBytes())
-	mempool.RemoveTxByKey(TxKey([]byte{0x06}), true)
+	require.NoError(t, mempool.RemoveTxByKey(TxKey([]byte{0x06}), true))
 	assert.EqualValues(t, 0, mempool.TxsBytes())
 
 }
 
+func TestMempoolCombinedStats(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	appConn, err := cc.NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop() // nolint:errcheck
+
+	config := cfg.ResetTestRoot("mempool_test")
+	defer os.RemoveAll(config.RootDir)
+	sidecar := NewCListSidecar(config.Sidecar, 0)
+	mempool := NewCListMempool(config.Mempool, appConn, 0, WithSidecar(sidecar))
+
+	// without a sidecar, only the mempool is reported
+	plain := NewCListMempool(config.Mempool, appConn, 0)
+	require.NoError(t, plain.CheckTx(types.Tx("plain"), nil, TxInfo{}))
+	assert.Equal(t, CombinedStats{MempoolTxs: 1, MempoolBytes: 5}, plain.CombinedStats())
+
+	require.NoError(t, mempool.CheckTx(types.Tx("first"), nil, TxInfo{}))
+	require.NoError(t, mempool.CheckTx(types.Tx("second"), nil, TxInfo{}))
+	require.NoError(t, mempool.CheckTx(types.Tx("order0"), nil, TxInfo{DesiredHeight: 1, BundleId: 0, BundleOrder: 0, BundleSize: 2}))
+	require.NoError(t, mempool.CheckTx(types.Tx("order1"), nil, TxInfo{DesiredHeight: 1, BundleId: 0, BundleOrder: 1, BundleSize: 2}))
+	require.NoError(t, mempool.CheckTx(types.Tx("partial"), nil, TxInfo{DesiredHeight: 1, BundleId: 1, BundleOrder: 0, BundleSize: 2}))
+
+	assert.Equal(t, CombinedStats{
+		MempoolTxs:     2,
+		MempoolBytes:   11,
+		SidecarTxs:     3,
+		SidecarBytes:   19,
+		SidecarBundles: 2,
+	}, mempool.CombinedStats())
+}
+
+func TestVerifyReapFits(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 3, DesiredHeight: 1, BundleId: 0})
+	memTxs := sidecar.ReapMaxTxs()
+	require.Len(t, memTxs, 3)
+
+	assert.NoError(t, VerifyReapFits(memTxs, 66, -1))
+	assert.NoError(t, VerifyReapFits(memTxs, -1, 0))
+	assert.Equal(t, ErrReapTooLarge{"bytes", 65, 66}, VerifyReapFits(memTxs, 65, -1))
+
+	withGas := []*MempoolTx{{tx: types.Tx("first"), gasWanted: 5}, {tx: types.Tx("second"), gasWanted: 6}}
+	assert.NoError(t, VerifyReapFits(withGas, -1, 11))
+	err := VerifyReapFits(withGas, -1, 10)
+	assert.Equal(t, ErrReapTooLarge{"gas", 10, 11}, err)
+	assert.EqualError(t, err, "reaped txs take 11 gas, over the max of 10")
+}
+
+func TestDiffReap(t *testing.T) {
+	txs := randomTxs(5)
+	reap := func(txs ...types.Tx) []*MempoolTx {
+		memTxs := make([]*MempoolTx, len(txs))
+		for i, tx := range txs {
+			memTxs[i] = &MempoolTx{tx: tx}
+		}
+		return memTxs
+	}
+
+	onlyA, onlyB := DiffReap(reap(txs[0], txs[1], txs[2]), reap(txs[0], txs[1], txs[2]))
+	assert.Empty(t, onlyA)
+	assert.Empty(t, onlyB)
+
+	// txs 1 and 2 reaped in another order aren't reported
+	onlyA, onlyB = DiffReap(reap(txs[0], txs[1], txs[2], txs[3]), reap(txs[2], txs[1], txs[4], txs[0]))
+	assert.Equal(t, [][TxKeySize]byte{TxKey(txs[3])}, onlyA)
+	assert.Equal(t, [][TxKeySize]byte{TxKey(txs[4])}, onlyB)
+
+	// a tx reaped twice by one reap and once by the other
+	onlyA, onlyB = DiffReap(reap(txs[0], txs[0]), reap(txs[0]))
+	assert.Equal(t, [][TxKeySize]byte{TxKey(txs[0])}, onlyA)
+	assert.Empty(t, onlyB)
+}
+
+func TestMempoolRemoveTxByKeyInBundle(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	appConn, err := cc.NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop() // nolint:errcheck
+
+	config := cfg.ResetTestRoot("mempool_test")
+	defer os.RemoveAll(config.RootDir)
+	sidecar := NewCListSidecar(config.Sidecar, 0)
+	mempool := NewCListMempool(config.Mempool, appConn, 0, WithSidecar(sidecar))
+
+	// the same tx sent both on its own and as an order of a bundle
+	bundleTx := types.Tx("bundled")
+	otherTx := types.Tx("other")
+	require.NoError(t, mempool.CheckTx(bundleTx, nil, TxInfo{}))
+	require.NoError(t, mempool.CheckTx(otherTx, nil, TxInfo{}))
+	require.NoError(t, mempool.CheckTx(bundleTx, nil, TxInfo{DesiredHeight: 1, BundleId: 0, BundleOrder: 0, BundleSize: 2}))
+	require.Equal(t, 2, mempool.Size())
+	require.Equal(t, 1, sidecar.Size())
+
+	// removing it from the mempool only is rejected
+	assert.Equal(t, ErrTxInBundle, mempool.RemoveTxByKey(TxKey(bundleTx), true))
+	assert.Equal(t, 2, mempool.Size())
+	assert.True(t, sidecar.HasTx(TxKey(bundleTx)))
+
+	require.NoError(t, mempool.RemoveTxByKey(TxKey(otherTx), true))
+	assert.Equal(t, 1, mempool.Size())
+
+	// once the sidecar no longer holds it, it can be removed
+	sidecar.Lock()
+	sidecar.Flush()
+	sidecar.Unlock()
+	require.NoError(t, mempool.RemoveTxByKey(TxKey(bundleTx), true))
+	assert.Zero(t, mempool.Size())
+}
+
 // This will non-deterministically catch some concurrency failures like
 // https://github.com/tendermint/tendermint/issues/3509
 // TODO: all of the tests should probably also run using the remote proxy app
@@ -1,13 +1,21 @@
 package mempool
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	abcicli "github.com/tendermint/tendermint/abci/client"
 	abci "github.com/tendermint/tendermint/abci/types"
+	cfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/libs/clist"
 	tmsync "github.com/tendermint/tendermint/libs/sync"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/proxy"
 	"github.com/tendermint/tendermint/types"
 )
 
@@ -21,15 +29,22 @@ type CListPriorityTxSidecar struct {
 	height                 int64 // the last block Update()'d to
 	heightForFiringAuction int64 // the height of the block to fire the auction for
 	txsBytes               int64 // total size of sidecar, in bytes
+	reapedHeight           int64 // the last height reaped for, see SidecarConfig.CurrentHeightPolicy
+	sealedHeight           int64 // the last height sealed, see ReapAndSeal
 
 	// notify listeners (ie. consensus) when txs are available
 	notifiedTxsAvailable bool
 	txsAvailable         chan struct{} // fires once for each height, when the mempool is not empty
 
+	// swapped by UpdateConfig with both configMtx and updateMtx held: read it
+	// directly with updateMtx held, and with currentConfig otherwise
+	configMtx tmsync.RWMutex
+	config    *cfg.SidecarConfig
+
 	txs    *clist.CList // concurrent linked-list of good SidecarTxs
 	txsMap sync.Map
 
-	// sync.Map: Key{height, bundleId} -> Bundle{
+	// sync.Map: Key{height, bundleId, sender} -> Bundle{
 	// // height int64
 	// // enforcedSize int64
 	// // currSize int64
@@ -37,45 +52,329 @@ type CListPriorityTxSidecar struct {
 	// // sync.Map bundleOrder -> *SidecarTx
 	// }
 	bundles     sync.Map
-	maxBundleId int64
+	maxBundleId int64 // highest bundle id held (atomic)
+	orderSeq    int64 // incremented for every order added, to track bundle progress
+
+	// lifetime bundle stats per peer, see PeerBundleStats
+	peerStatsMtx tmsync.Mutex
+	peerStats    map[uint16]*PeerStats
+
+	// senders of the bundles included in the last reap, see LastReapWinners
+	lastReapMtx     tmsync.Mutex
+	lastReapWinners []uint16
+	lastReapAudit   []ReapDecision // see LastReapAudit
+
+	// ring of the last firedHeightsHistory heights auctions fired for, see
+	// FiredHeights
+	firedHeightsMtx tmsync.Mutex
+	firedHeights    [firedHeightsHistory]int64
+	numFiredHeights int       // number of heights recorded, including overwritten ones
+	firedAtHeight   int64     // the last height fired, see SidecarAuctionToCommitSeconds
+	firedAt         time.Time // when the auction for firedAtHeight first fired
+
+	// what the sidecar did since the last update, published by the next one
+	// as a SidecarHeightStats event
+	heightReapedBundles  int64
+	heightReapedBytes    int64
+	heightEvictedBundles int64
+
+	// closed and replaced every time an order is added, see ReapMaxTxsWithDeadline
+	orderAddedMtx tmsync.Mutex
+	orderAdded    chan struct{}
 
 	updateMtx tmsync.RWMutex
 
 	// Keep a cache of already-seen txs.
 	// This reduces the pressure on the proxyApp.
 	cache txCache
+
+	// if set, txs are validated with CheckTx before being added to a bundle
+	proxyAppConn proxy.AppConnMempool
+
+	// if set, called on every bundle that becomes complete, see SetBundleAdmissionHook
+	admissionHook BundleAdmissionHook
+
+	// if set, called on every bundle evicted or flushed, see SetBundleEvictionHook
+	evictionHook BundleEvictionHook
+
+	// peers whose bundles aren't reaped, see SetReapExcludedPeers
+	reapExcludePeers map[uint16]bool
+
+	metrics *Metrics
+
+	// the sidecar's clock, see WithSidecarClock
+	now func() time.Time
+
+	// bundle and auction events are published on it, see WithSidecarEventBus
+	eventBus types.SidecarEventPublisher
+
+	// set to 1 while the node is catching up, see SetSyncing (atomic)
+	syncing int32
+
+	// accepted bundles are appended to it, if set, see InitBundleExport
+	exportMtx tmsync.Mutex
+	export    *bundleExport
+
+	// slots for the bundles being validated by AddBundle, nil if unlimited
+	bundleChecks chan struct{}
 }
 
 var _ PriorityTxSidecar = &CListPriorityTxSidecar{}
 
+// firedHeightsHistory is the number of fired auction heights kept for
+// FiredHeights
+const firedHeightsHistory = 100
+
+// Key indexes the sidecar's bundles. Bundles are namespaced by sender only
+// if SidecarConfig.NamespaceBundlesBySender is set, otherwise sender is
+// always UnknownPeerID.
 type Key struct {
 	height, bundleId int64
+	sender           uint16
+}
+
+// bundleKey returns the key of the bundle txInfo is an order of.
+func (sc *CListPriorityTxSidecar) bundleKey(txInfo TxInfo) Key {
+	sender := UnknownPeerID
+	if sc.config.NamespaceBundlesBySender {
+		sender = txInfo.SenderID
+	}
+	return Key{txInfo.DesiredHeight, txInfo.BundleId, sender}
+}
+
+// bundleKeys returns the keys of the bundles for height, in reap order: by
+// bundle id, then sender.
+func (sc *CListPriorityTxSidecar) bundleKeys(height int64) []Key {
+	keys := make([]Key, 0)
+	sc.bundles.Range(func(key, _ interface{}) bool {
+		if key := key.(Key); key.height == height {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].bundleId != keys[j].bundleId {
+			return keys[i].bundleId < keys[j].bundleId
+		}
+		return keys[i].sender < keys[j].sender
+	})
+	return keys
+}
+
+// loadBundle returns the first bundle, in reap order, with bundleId at height.
+func (sc *CListPriorityTxSidecar) loadBundle(height, bundleId int64) (*Bundle, bool) {
+	for _, key := range sc.bundleKeys(height) {
+		if key.bundleId != bundleId {
+			continue
+		}
+		if bundle, ok := sc.bundles.Load(key); ok {
+			return bundle.(*Bundle), true
+		}
+	}
+	return nil, false
+}
+
+// SetSyncing sets whether the node is catching up, e.g. fast syncing. While
+// it is, AddTx rejects every tx with ErrNodeSyncing, as bundles for heights
+// the node is catching up past are never reaped.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) SetSyncing(syncing bool) {
+	atomic.StoreInt32(&sc.syncing, boolToInt32(syncing))
+}
+
+// IsSyncing returns true if the node is catching up, see SetSyncing.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) IsSyncing() bool {
+	return atomic.LoadInt32(&sc.syncing) == 1
 }
 
-// NewCListSidecar returns a new sidecar with the given configuration
+// CListSidecarOption sets an optional parameter on the sidecar.
+type CListSidecarOption func(*CListPriorityTxSidecar)
+
+// NewCListSidecar returns a new sidecar with the given configuration. It panics
+// if the configuration doesn't pass SidecarConfig.Validate; use
+// NewCListSidecarE to get the error instead.
 func NewCListSidecar(
+	config *cfg.SidecarConfig,
 	height int64,
+	options ...CListSidecarOption,
 ) *CListPriorityTxSidecar {
+	sidecar, err := NewCListSidecarE(config, height, options...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return sidecar
+}
+
+// NewCListSidecarE is like NewCListSidecar, but returns an error if the
+// configuration doesn't pass SidecarConfig.Validate.
+func NewCListSidecarE(
+	config *cfg.SidecarConfig,
+	height int64,
+	options ...CListSidecarOption,
+) (*CListPriorityTxSidecar, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid sidecar config: %w", err)
+	}
 	sidecar := &CListPriorityTxSidecar{
+		config:                 config,
 		txs:                    clist.New(),
 		height:                 height,
 		heightForFiringAuction: height + 1,
+		orderAdded:             make(chan struct{}),
+		metrics:                NopMetrics(),
+		eventBus:               types.NopEventBus{},
+		peerStats:              make(map[uint16]*PeerStats),
+		now:                    time.Now,
 	}
 	// TODO: update
 	sidecar.cache = newMapTxCache(10000)
-	return sidecar
+	if config.MaxConcurrentBundleChecks > 0 {
+		sidecar.bundleChecks = make(chan struct{}, config.MaxConcurrentBundleChecks)
+	}
+	for _, option := range options {
+		option(sidecar)
+	}
+	sidecar.metrics = sidecar.metrics.withSidecarLabel(config.MetricsLabel)
+	return sidecar, nil
+}
+
+// WithSidecarProxyAppConn has the sidecar run CheckTx on every tx before adding
+// it to a bundle. If the app rejects a tx, its whole bundle is dropped, since a
+// partial bundle is invalid.
+func WithSidecarProxyAppConn(proxyAppConn proxy.AppConnMempool) CListSidecarOption {
+	return func(sc *CListPriorityTxSidecar) { sc.proxyAppConn = proxyAppConn }
+}
+
+// WithSidecarABCIClient is like WithSidecarProxyAppConn, but runs CheckTx over
+// the given client, e.g. a connection dedicated to the sidecar, so that bundle
+// checks don't contend with the mempool's CheckTx. Note local clients made by
+// the same ClientCreator share a lock, so a dedicated local client must come
+// from its own creator.
+func WithSidecarABCIClient(client abcicli.Client) CListSidecarOption {
+	return WithSidecarProxyAppConn(proxy.NewAppConnMempool(client))
+}
+
+// SetBundleAdmissionHook sets a hook run on every bundle as it becomes
+// complete, e.g. to simulate it. A bundle isn't reapable until the hook
+// accepts it, and is evicted if the hook returns an error, which is returned
+// by the AddTx call that completed it. The hook runs outside of the sidecar's
+// lock, so it can be slow, but it may be called concurrently.
+// Only applies to bundles started after the hook is set. A nil hook admits
+// every bundle.
+func (sc *CListPriorityTxSidecar) SetBundleAdmissionHook(hook BundleAdmissionHook) {
+	sc.updateMtx.Lock()
+	defer sc.updateMtx.Unlock()
+	sc.admissionHook = hook
+}
+
+// SetBundleEvictionHook sets a hook notified of every bundle evicted from
+// the sidecar, or dropped by Flush. Flush notifies it in reap order: by
+// height, bundle id, then sender. The hook runs with the sidecar's lock held,
+// so it must be quick, mustn't call back into the sidecar, and may be called
+// concurrently. A nil hook is never called.
+func (sc *CListPriorityTxSidecar) SetBundleEvictionHook(hook BundleEvictionHook) {
+	sc.updateMtx.Lock()
+	defer sc.updateMtx.Unlock()
+	sc.evictionHook = hook
+}
+
+// SetReapExcludedPeers has reaps skip the bundles sent by peers, e.g. peers
+// blacklisted since their bundles were accepted. The bundles stay in the
+// sidecar, and are reaped again once their peer is no longer excluded.
+// Bundles are attributed to the peer that sent their first order. A nil map
+// excludes no one.
+func (sc *CListPriorityTxSidecar) SetReapExcludedPeers(peers map[uint16]bool) {
+	excluded := make(map[uint16]bool, len(peers))
+	for peerID, exclude := range peers {
+		if exclude {
+			excluded[peerID] = true
+		}
+	}
+	sc.updateMtx.Lock()
+	defer sc.updateMtx.Unlock()
+	sc.reapExcludePeers = excluded
+}
+
+// admitBundle runs the admission hook on a bundle that just became complete,
+// making it reapable or evicting it.
+func (sc *CListPriorityTxSidecar) admitBundle(bundle *Bundle) error {
+	sc.updateMtx.RLock()
+	hook := sc.admissionHook
+	sc.updateMtx.RUnlock()
+
+	txs := make([]types.Tx, 0, bundle.enforcedSize)
+	for bundleOrderIter := int64(0); bundleOrderIter < bundle.enforcedSize; bundleOrderIter++ {
+		if scTx, ok := bundle.orderedTxsMap.Load(bundleOrderIter); ok {
+			txs = append(txs, scTx.(*SidecarTx).tx)
+		}
+	}
+	meta := bundleMeta(bundle)
+
+	var err error
+	if hook != nil {
+		err = hook(txs, meta)
+	}
+
+	sc.updateMtx.RLock()
+	defer sc.updateMtx.RUnlock()
+	defer sc.notifyOrderAdded()
+	if err == nil {
+		atomic.StoreInt32(&bundle.pendingAdmission, 0)
+		return nil
+	}
+	fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx(): admission hook rejected bundle with id %d at height %d, evicting: %v", bundle.bundleId, bundle.desiredHeight, err))
+	// the bundle may have been dropped or replaced while the hook ran, which
+	// removeBundle checks for
+	sc.removeBundle(bundle.key(), bundle)
+	return ErrBundleNotAdmitted{bundle.bundleId, bundle.desiredHeight, err}
+}
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// WithSidecarMetrics sets the metrics.
+func WithSidecarMetrics(metrics *Metrics) CListSidecarOption {
+	return func(sc *CListPriorityTxSidecar) { sc.metrics = metrics }
+}
+
+// WithSidecarClock sets the clock the sidecar reads the time from, which
+// defaults to time.Now.
+func WithSidecarClock(now func() time.Time) CListSidecarOption {
+	return func(sc *CListPriorityTxSidecar) { sc.now = now }
+}
+
+// WithSidecarEventBus sets the event bus bundle and auction events are
+// published on.
+func WithSidecarEventBus(eventBus types.SidecarEventPublisher) CListSidecarOption {
+	return func(sc *CListPriorityTxSidecar) { sc.eventBus = eventBus }
+}
+
+// bundleEventData returns the payload of bundle's events.
+func bundleEventData(bundle *Bundle) types.EventDataSidecarBundle {
+	return types.EventDataSidecarBundle{
+		Height:     bundle.desiredHeight,
+		BundleId:   bundle.bundleId,
+		BundleSize: bundle.enforcedSize,
+		SenderID:   bundle.senderID,
+	}
 }
 
 func (sc *CListPriorityTxSidecar) PrettyPrintBundles() {
 	fmt.Println(fmt.Sprintf("-------------"))
-	for bundleIdIter := 0; bundleIdIter <= int(sc.maxBundleId); bundleIdIter++ {
-		bundleIdIter := int64(bundleIdIter)
-		if bundle, ok := sc.bundles.Load(Key{sc.heightForFiringAuction, bundleIdIter}); ok {
+	for _, key := range sc.bundleKeys(sc.heightForFiringAuction) {
+		if bundle, ok := sc.bundles.Load(key); ok {
 			bundle := bundle.(*Bundle)
-			fmt.Println(fmt.Sprintf("BUNDLE ID: %d", bundleIdIter))
+			fmt.Println(fmt.Sprintf("BUNDLE ID: %d", key.bundleId))
 
 			innerOrderMap := bundle.orderedTxsMap
-			bundleSize := bundle.currSize
+			bundleSize := atomic.LoadInt64(&bundle.currSize)
 			for bundleOrderIter := 0; bundleOrderIter < int(bundleSize); bundleOrderIter++ {
 				bundleOrderIter := int64(bundleOrderIter)
 
@@ -118,7 +417,101 @@ func (sc *CListPriorityTxSidecar) notifyTxsAvailable() {
 //--------------------------------------------------------------------------------
 
 // TODO: Update to AddTx(tx types.Tx, txInfo TxInfo, order int64) error
+//
+// txInfo.BundleOrder counts from SidecarConfig.BundleOrderBase, bundle orders
+// are numbered from 0 from then on.
 func (sc *CListPriorityTxSidecar) AddTx(tx types.Tx, txInfo TxInfo) error {
+	_, err := sc.addTxCompleted(tx, txInfo)
+	return err
+}
+
+// addTxCompleted is AddTx, also returning whether tx completed the bundle it
+// was added to, i.e. the one at txInfo's height, bundle id and sender, not
+// another sender's bundle with the same id.
+func (sc *CListPriorityTxSidecar) addTxCompleted(tx types.Tx, txInfo TxInfo) (bool, error) {
+	txInfo.BundleOrder -= int64(sc.currentConfig().BundleOrderBase)
+	completed, err := sc.addCheckedTx(tx, txInfo, nil)
+	return completed != nil && err == nil, err
+}
+
+// addCheckedTx is AddTx, for a tx that already went through CheckTx with
+// result checkRes, if not nil. It returns the bundle tx completed, if any.
+func (sc *CListPriorityTxSidecar) addCheckedTx(tx types.Tx, txInfo TxInfo, checkRes *abci.ResponseCheckTx) (*Bundle, error) {
+	completed, err := sc.addTx(tx, txInfo, checkRes)
+	if completed != nil && atomic.LoadInt32(&completed.pendingAdmission) == 1 {
+		err = sc.admitBundle(completed)
+	}
+	if completed != nil && err == nil {
+		if err := sc.eventBus.PublishEventSidecarBundleAccepted(bundleEventData(completed)); err != nil {
+			fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx(): failed publishing accepted event for bundle with id %d: %v", completed.bundleId, err))
+		}
+		sc.exportBundle(completed)
+	}
+	if err != nil && err != ErrTxInCache && err != ErrTxAlreadyInBundle && err != ErrNodeSyncing {
+		sc.updatePeerStats(txInfo.SenderID, func(stats *PeerStats) { stats.RejectedTxs++ })
+	}
+	return completed, err
+}
+
+// bundleConflict counts and returns the error for a tx sent for an order its
+// bundle already holds a different tx at, i.e. for a bundle id reused at the
+// same height by the same sender for different content.
+func (sc *CListPriorityTxSidecar) bundleConflict(txInfo TxInfo) error {
+	fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx() skip tx... bundleId %d, height %d, bundleOrder %d already holds a different tx", txInfo.BundleId, txInfo.DesiredHeight, txInfo.BundleOrder))
+	sc.metrics.ConflictingSidecarBundleTxs.Add(1)
+	sc.evictMalformedBundle(txInfo, "another tx at a taken order")
+	return ErrBundleConflict{
+		txInfo.BundleId,
+		txInfo.DesiredHeight,
+		txInfo.BundleOrder,
+	}
+}
+
+// evictMalformedBundle evicts the bundle txInfo is an order of, if any, in
+// strict mode, counting it against the peer that sent the order. reason
+// says how the order is inconsistent with the bundle. A bundle from another
+// peer is kept, see checkBundleSender.
+func (sc *CListPriorityTxSidecar) evictMalformedBundle(txInfo TxInfo, reason string) {
+	if !sc.config.StrictMode {
+		return
+	}
+	key := sc.bundleKey(txInfo)
+	if bundle, ok := sc.bundles.Load(key); ok && bundle.(*Bundle).senderID == txInfo.SenderID {
+		sc.strictEvict(key, bundle.(*Bundle), txInfo.SenderID, reason)
+	}
+}
+
+// checkBundleSender returns ErrBundleSenderMismatch if tx, an order of
+// bundle, isn't from the peer that sent the bundle's first order. The bundle
+// is left alone, so a spoofed order can't evict it, and tx leaves the cache,
+// so the bundle's own sender can still send it.
+func (sc *CListPriorityTxSidecar) checkBundleSender(bundle *Bundle, tx types.Tx, txInfo TxInfo) error {
+	if txInfo.SenderID == bundle.senderID {
+		return nil
+	}
+	fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx() skip tx... order for bundleId %d at height %d from peer %d, but the bundle is from peer %d", txInfo.BundleId, txInfo.DesiredHeight, txInfo.SenderID, bundle.senderID))
+	sc.cache.Remove(tx)
+	return ErrBundleSenderMismatch{
+		txInfo.BundleId,
+		txInfo.DesiredHeight,
+		txInfo.SenderID,
+		bundle.senderID,
+	}
+}
+
+// strictEvict evicts a malformed bundle in strict mode, counting it against
+// peerID, see SidecarConfig.StrictMode.
+func (sc *CListPriorityTxSidecar) strictEvict(key interface{}, bundle *Bundle, peerID uint16, reason string) {
+	if !sc.evictBundle(key, bundle) {
+		return
+	}
+	fmt.Println(fmt.Sprintf("[mev-tendermint]: strict mode, evicted bundle with id %d at height %d for %s from peer %d", bundle.bundleId, bundle.desiredHeight, reason, peerID))
+	sc.updatePeerStats(peerID, func(stats *PeerStats) { stats.StrictEvictions++ })
+}
+
+// addTx does the work of AddTx. If tx completed a bundle, the bundle is
+// returned for AddTx to run the admission hook on, if it's pending admission.
+func (sc *CListPriorityTxSidecar) addTx(tx types.Tx, txInfo TxInfo, checkRes *abci.ResponseCheckTx) (*Bundle, error) {
 
 	sc.updateMtx.RLock()
 	// use defer to unlock mutex because application (*local client*) might panic
@@ -126,6 +519,21 @@ func (sc *CListPriorityTxSidecar) AddTx(tx types.Tx, txInfo TxInfo) error {
 
 	fmt.Println(fmt.Sprintf("[mev-tendermint]: STARTING TO ADD TRANSACTION %.20q TO SIDECAR! with bundleId %d, bundleOrder %d, desiredHeight %d, bundleSize %d", tx, txInfo.BundleId, txInfo.BundleOrder, txInfo.DesiredHeight, txInfo.BundleSize))
 
+	// bundles for heights a syncing node will only catch up past are pointless
+	if sc.IsSyncing() {
+		fmt.Println("[mev-tendermint]: AddTx() skip tx... node is syncing")
+		return nil, ErrNodeSyncing
+	}
+
+	// the count cap is independent of the sidecar's byte limits
+	if maxTxs := sc.config.MaxSidecarTxs; maxTxs > 0 && sc.Size() >= maxTxs {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx() skip tx... sidecar already holds the maximum of %d txs", maxTxs))
+		return nil, ErrSidecarIsFull{
+			sc.Size(),
+			maxTxs,
+		}
+	}
+
 	// don't add any txs already in cache
 	if !sc.cache.Push(tx) {
 		fmt.Println("[mev-tendermint]: trying to add tx to sidecar AddTx - but already in cache!")
@@ -141,61 +549,158 @@ func (sc *CListPriorityTxSidecar) AddTx(tx types.Tx, txInfo TxInfo) error {
 			scTx.senders.LoadOrStore(txInfo.SenderID, true)
 		}
 
-		return ErrTxInCache
+		return nil, ErrTxInCache
 	}
 
+	// copy tx, so a caller reusing its buffer can't corrupt the stored tx
+	tx = append(make(types.Tx, 0, len(tx)), tx...)
+
 	scTx := &SidecarTx{
 		desiredHeight: txInfo.DesiredHeight,
 		tx:            tx,
 		bundleId:      txInfo.BundleId,
+		bundleSender:  sc.bundleKey(txInfo).sender,
 		bundleOrder:   txInfo.BundleOrder,
 		bundleSize:    txInfo.BundleSize,
+		execHint:      txInfo.ExecHint,
 		// TODO: gas
 	}
 
 	// -------- BASIC CHECKS ON TX INFO ---------
 
-	// Can't add transactions asking to be included in a height for auction we're not on
-	if txInfo.DesiredHeight < sc.heightForFiringAuction {
+	// Can't add transactions asking to be included in a height for auction we're not on.
+	// Update holds the lock while it moves the height on, so a committed height
+	// is never resurrected by an order racing its Update.
+	if txInfo.DesiredHeight < sc.heightForFiringAuction && !sc.acceptsLateOrder(txInfo) {
 		fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx() skip tx... trying to add a tx for height %d whereas height for curr auction is %d", txInfo.DesiredHeight, sc.heightForFiringAuction))
-		return ErrWrongHeight{
+		sc.evictMalformedBundle(txInfo, "an order for a past height")
+		return nil, ErrBundleHeightInPast{
+			txInfo.BundleId,
+			txInfo.DesiredHeight,
+			sc.height,
+		}
+	}
+
+	// Depending on policy, the auction height is closed to orders once reaping for it started
+	if txInfo.DesiredHeight == sc.heightForFiringAuction && sc.auctionHeightClosed() {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx() skip tx... trying to add a tx for height %d after reaping for it started", txInfo.DesiredHeight))
+		return nil, ErrWrongHeight{
 			int(txInfo.DesiredHeight),
-			int(sc.heightForFiringAuction),
+			int(sc.heightForFiringAuction + 1),
+		}
+	}
+
+	// an order before the first one, e.g. 0 when orders count from 1
+	if txInfo.BundleOrder < 0 {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx() skip tx... trying to insert a tx for bundle at order %d, before its first order", txInfo.BundleOrder+int64(sc.config.BundleOrderBase)))
+		sc.evictMalformedBundle(txInfo, "an order before its first")
+		return nil, ErrTxMalformedForBundle{
+			txInfo.BundleId,
+			txInfo.BundleSize,
+			txInfo.DesiredHeight,
+			txInfo.BundleOrder,
 		}
 	}
 
 	// revert if tx asking to be included has an order greater/equal to size
 	if txInfo.BundleOrder >= txInfo.BundleSize {
 		fmt.Println("[mev-tendermint]: AddTx() skip tx... trying to insert a tx for bundle at an order greater than the size of the bundle... THIS IS PROBABLY A FATAL ERROR")
-		return ErrTxMalformedForBundle{
+		sc.evictMalformedBundle(txInfo, "an order past its size")
+		return nil, ErrBundleOrderBeyondSize{
 			txInfo.BundleId,
-			txInfo.BundleSize,
 			txInfo.DesiredHeight,
+			txInfo.BundleSize,
 			txInfo.BundleOrder,
 		}
 	}
 
+	// -------- APP VALIDITY CHECKS ---------
+
+	if sc.proxyAppConn != nil {
+		res := checkRes
+		if res == nil {
+			var err error
+			res, err = sc.proxyAppConn.CheckTxSync(abci.RequestCheckTx{Tx: tx})
+			if err != nil {
+				sc.cache.Remove(tx)
+				return nil, err
+			}
+		}
+		if res.Code != abci.CodeTypeOK {
+			fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx() app rejected tx with code %d, dropping bundleId %d at height %d", res.Code, txInfo.BundleId, txInfo.DesiredHeight))
+			sc.cache.Remove(tx)
+			key := sc.bundleKey(txInfo)
+			if bundle, ok := sc.bundles.Load(key); ok {
+				// a spoofed order can't get another peer's bundle dropped
+				if err := sc.checkBundleSender(bundle.(*Bundle), tx, txInfo); err != nil {
+					return nil, err
+				}
+				sc.removeBundle(key, bundle.(*Bundle))
+			}
+			sc.metrics.RejectedSidecarBundles.Add(1)
+			sc.updatePeerStats(txInfo.SenderID, func(stats *PeerStats) { stats.ABCIRejectedBundles++ })
+			return nil, ErrTxRejectedForBundle{
+				txInfo.BundleId,
+				txInfo.DesiredHeight,
+				res.Code,
+			}
+		}
+		scTx.gasWanted = res.GasWanted
+	}
+
 	// -------- BUNDLE EXISTENCE CHECKS ---------
 
 	var bundle *Bundle
 	// load existing bundle, or MAKE NEW if not
-	existingBundle, _ := sc.bundles.LoadOrStore(Key{txInfo.DesiredHeight, txInfo.BundleId}, &Bundle{
-		desiredHeight: txInfo.DesiredHeight,
-		bundleId:      txInfo.BundleId,
-		currSize:      int64(0),
-		enforcedSize:  txInfo.BundleSize,
+	maxHeight := txInfo.MaxHeight
+	if maxHeight < txInfo.DesiredHeight {
+		maxHeight = txInfo.DesiredHeight
+	}
+	key := sc.bundleKey(txInfo)
+	existingBundle, loaded := sc.bundles.LoadOrStore(key, &Bundle{
+		desiredHeight:   txInfo.DesiredHeight,
+		maxHeight:       maxHeight,
+		bundleId:        txInfo.BundleId,
+		sender:          key.sender,
+		currSize:        int64(0),
+		enforcedSize:    txInfo.BundleSize,
+		minOrdersToReap: txInfo.MinOrdersToReap,
 		// TODO: add from gossip info?
 		gasWanted:     int64(0),
 		orderedTxsMap: &sync.Map{},
+		senderID:      txInfo.SenderID,
+		// bundles are only reapable once the admission hook, if any, accepts them
+		pendingAdmission: boolToInt32(sc.admissionHook != nil),
 	})
 	bundle = existingBundle.(*Bundle)
+	if !loaded {
+		sc.updatePeerStats(txInfo.SenderID, func(stats *PeerStats) { stats.AcceptedBundles++ })
+	}
+
+	// -------- BUNDLE SENDER CHECKS ---------
+
+	// ahead of the checks below, so another peer's order can't evict the bundle
+	if err := sc.checkBundleSender(bundle, tx, txInfo); err != nil {
+		return nil, err
+	}
 
 	// -------- BUNDLE SIZE CHECKS ---------
 
 	// check if bundle is asking for a different size than one already stored
 	if txInfo.BundleSize != bundle.enforcedSize {
 		fmt.Println("[mev-tendermint]: AddTx() skip tx... Trying to insert a tx with a size different than what's said by other txs for this bundle?? ... THIS IS PROBABLY A FATAL ERROR")
-		return ErrTxMalformedForBundle{
+		sc.metrics.ConflictingSidecarBundleTxs.Add(1)
+		sc.evictMalformedBundle(txInfo, "an order with another size")
+		// the bundle keeps the size it was declared with
+		if txInfo.BundleOrder >= bundle.enforcedSize {
+			return nil, ErrBundleOrderBeyondSize{
+				txInfo.BundleId,
+				txInfo.DesiredHeight,
+				bundle.enforcedSize,
+				txInfo.BundleOrder,
+			}
+		}
+		return nil, ErrTxMalformedForBundle{
 			txInfo.BundleId,
 			txInfo.BundleSize,
 			txInfo.DesiredHeight,
@@ -205,9 +710,12 @@ func (sc *CListPriorityTxSidecar) AddTx(tx types.Tx, txInfo TxInfo) error {
 
 	// Can't add transactions if the bundle is already full
 	// check if the current size of this bundle is greater than the expected size for the bundle, if so skip
-	if bundle.currSize >= bundle.enforcedSize {
+	if bundle.isComplete() {
 		fmt.Println("[mev-tendermint]: AddTx() skip tx... already full for this BundleId... THIS IS PROBABLY A FATAL ERROR")
-		return ErrBundleFull{
+		if existing, ok := bundle.orderedTxsMap.Load(txInfo.BundleOrder); ok && !bytes.Equal(existing.(*SidecarTx).tx, tx) {
+			return nil, sc.bundleConflict(txInfo)
+		}
+		return nil, ErrBundleFull{
 			txInfo.BundleId,
 			txInfo.BundleSize,
 		}
@@ -215,26 +723,34 @@ func (sc *CListPriorityTxSidecar) AddTx(tx types.Tx, txInfo TxInfo) error {
 
 	// -------- TX INSERTION INTO BUNDLE ---------
 
+	completed := false // whether tx is the order that completed the bundle
+
 	// get the map of order -> scTx
 	orderedTxsMap := bundle.orderedTxsMap
 
 	// TODO: could add check to not add if bundleSize already over limit!
 	// if we already have a tx at this bundleId, bundleOrder, and height, then skip this one!
-	if _, loaded := orderedTxsMap.LoadOrStore(txInfo.BundleOrder, scTx); loaded {
+	if existing, loaded := orderedTxsMap.LoadOrStore(txInfo.BundleOrder, scTx); loaded {
 		// if we had the tx already, then skip
-		// TODO: return error
 		fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx() skip tx... already have a tx for bundleId %d, height %d, bundleOrder %d", txInfo.BundleId, scTx.desiredHeight, txInfo.BundleOrder))
-		return nil
+		if bytes.Equal(existing.(*SidecarTx).tx, tx) {
+			return nil, ErrTxAlreadyInBundle
+		}
+		return nil, sc.bundleConflict(txInfo)
 	} else {
 		// if we added, then increment bundle size for bundleId
-		atomic.AddInt64(&bundle.currSize, int64(1))
+		if txInfo.Pinned {
+			atomic.StoreInt32(&bundle.pinned, 1)
+		}
+		completed = atomic.AddInt64(&bundle.currSize, int64(1)) == bundle.enforcedSize
+		atomic.StoreInt64(&bundle.lastProgress, atomic.AddInt64(&sc.orderSeq, 1))
+		sc.notifyOrderAdded()
 	}
 
 	// -------- UPDATE MAX BUNDLE ---------
 
-	if txInfo.BundleId >= sc.maxBundleId {
+	if sc.raiseMaxBundleId(txInfo.BundleId) {
 		fmt.Println("[mev-tendermint]: AddTx(): updating maxBundleId to", txInfo.BundleId)
-		sc.maxBundleId = txInfo.BundleId
 	}
 
 	// -------- TX INSERTION INTO MAIN TXS LIST ---------
@@ -245,6 +761,17 @@ func (sc *CListPriorityTxSidecar) AddTx(tx types.Tx, txInfo TxInfo) error {
 	atomic.AddInt64(&sc.txsBytes, int64(len(scTx.tx)))
 	fmt.Println("[mev-tendermint]: AddTx(): actually added the tx to the sc.txs CList, sidecar size is now", sc.Size())
 
+	if completed && !bundle.lateDeadline.IsZero() {
+		bundle = sc.promoteLateBundle(bundle)
+	}
+
+	if sc.config.MaxBufferedOrders > 0 {
+		sc.evictIncompleteBundlesOverLimit()
+	}
+	if sc.config.SoftMaxTxsBytes > 0 {
+		sc.evictIncompleteBundlesOverSoftMaxBytes()
+	}
+
 	// TODO: in the future, refactor to only notifyTxsAvailable when we have at least one full bundle
 	if sc.Size() > 0 {
 		sc.notifyTxsAvailable()
@@ -252,9 +779,261 @@ func (sc *CListPriorityTxSidecar) AddTx(tx types.Tx, txInfo TxInfo) error {
 
 	fmt.Println("[mev-tendermint]: ADDING SIDECAR TX FUNCTION COMPLETION")
 
+	if completed && bundle != nil {
+		return bundle, nil
+	}
+	return nil, nil
+}
+
+// AddBundle adds txs to the sidecar as a whole bundle, with txs[i] at
+// bundleOrder i. The bundle size and order in txInfo are ignored, all other
+// fields apply to every tx. It stops at the first tx AddTx rejects, returning
+// its error along with a receipt for the txs accepted before it.
+// If txs are validated with CheckTx, they all are before any is added, up to
+// CheckTxWorkers at a time: if the app rejects one, none are added.
+func (sc *CListPriorityTxSidecar) AddBundle(txs types.Txs, txInfo TxInfo) (BundleReceipt, error) {
+	receipt := BundleReceipt{
+		DesiredHeight: txInfo.DesiredHeight,
+		BundleId:      txInfo.BundleId,
+	}
+	// don't check a bundle AddTx would reject anyway
+	if sc.IsSyncing() {
+		return receipt, ErrNodeSyncing
+	}
+	// don't start a bundle the sidecar has no room to finish
+	if maxTxs := sc.currentConfig().MaxSidecarTxs; maxTxs > 0 && sc.Size()+len(txs) > maxTxs {
+		return receipt, ErrSidecarIsFull{
+			sc.Size(),
+			maxTxs,
+		}
+	}
+	var checkResponses []*abci.ResponseCheckTx
+	if sc.proxyAppConn != nil {
+		if err := sc.acquireBundleCheck(txInfo); err != nil {
+			return receipt, err
+		}
+		var err error
+		checkResponses, err = sc.checkBundleTxs(txs)
+		sc.releaseBundleCheck()
+		if err != nil {
+			return receipt, err
+		}
+		for _, res := range checkResponses {
+			if res.Code != abci.CodeTypeOK {
+				fmt.Println(fmt.Sprintf("[mev-tendermint]: AddBundle() app rejected tx with code %d, dropping bundleId %d at height %d", res.Code, txInfo.BundleId, txInfo.DesiredHeight))
+				sc.metrics.RejectedSidecarBundles.Add(1)
+				sc.updatePeerStats(txInfo.SenderID, func(stats *PeerStats) { stats.ABCIRejectedBundles++ })
+				return receipt, ErrTxRejectedForBundle{
+					txInfo.BundleId,
+					txInfo.DesiredHeight,
+					res.Code,
+				}
+			}
+		}
+	}
+
+	txInfo.BundleSize = int64(len(txs))
+	for i, tx := range txs {
+		txInfo.BundleOrder = int64(i)
+		var checkRes *abci.ResponseCheckTx
+		if checkResponses != nil {
+			checkRes = checkResponses[i]
+		}
+		if _, err := sc.addCheckedTx(tx, txInfo, checkRes); err != nil {
+			return receipt, err
+		}
+		receipt.NumTxs++
+		receipt.TotalBytes += int64(len(tx))
+		if e, ok := sc.txsMap.Load(TxKey(tx)); ok {
+			receipt.TotalGas += e.(*clist.CElement).Value.(*SidecarTx).gasWanted
+		}
+	}
+	return receipt, nil
+}
+
+// acquireBundleCheck takes one of the MaxConcurrentBundleChecks slots for
+// validating a bundle, waiting for one to free up or failing, depending on
+// BundleChecksOverflowPolicy.
+func (sc *CListPriorityTxSidecar) acquireBundleCheck(txInfo TxInfo) error {
+	if sc.bundleChecks == nil {
+		return nil
+	}
+	select {
+	case sc.bundleChecks <- struct{}{}:
+		return nil
+	default:
+	}
+	config := sc.currentConfig()
+	if config.BundleChecksOverflowPolicy == cfg.SidecarBundleChecksReject {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: AddBundle() %d bundles already being validated, rejecting bundleId %d at height %d", config.MaxConcurrentBundleChecks, txInfo.BundleId, txInfo.DesiredHeight))
+		return ErrBundleChecksBusy{
+			txInfo.BundleId,
+			txInfo.DesiredHeight,
+			config.MaxConcurrentBundleChecks,
+		}
+	}
+	sc.bundleChecks <- struct{}{}
 	return nil
 }
 
+// releaseBundleCheck frees the slot taken by acquireBundleCheck.
+func (sc *CListPriorityTxSidecar) releaseBundleCheck() {
+	if sc.bundleChecks != nil {
+		<-sc.bundleChecks
+	}
+}
+
+// ImportBundles adds bundles to the sidecar with AddBundle, one after the
+// other, returning a receipt for each with the error it was rejected with, if
+// any. A rejected bundle doesn't stop the import.
+func (sc *CListPriorityTxSidecar) ImportBundles(bundles []BundleImport) []BundleReceipt {
+	receipts := make([]BundleReceipt, len(bundles))
+	for i, bundle := range bundles {
+		receipt, err := sc.AddBundle(bundle.Txs, bundle.TxInfo)
+		receipt.Err = err
+		receipts[i] = receipt
+	}
+	return receipts
+}
+
+// checkBundleTxs runs CheckTx on txs, on up to CheckTxWorkers txs at a time,
+// without holding the lock. It stops early once the app rejects a tx.
+func (sc *CListPriorityTxSidecar) checkBundleTxs(txs types.Txs) ([]*abci.ResponseCheckTx, error) {
+	workers := sc.currentConfig().CheckTxWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	responses := make([]*abci.ResponseCheckTx, len(txs))
+	errs := make([]error, len(txs))
+	var rejected int32
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, tx := range txs {
+		sem <- struct{}{}
+		if atomic.LoadInt32(&rejected) == 1 {
+			// the bundle is rejected anyway, the other txs don't matter
+			responses[i] = &abci.ResponseCheckTx{Code: abci.CodeTypeOK}
+			<-sem
+			continue
+		}
+		wg.Add(1)
+		go func(i int, tx types.Tx) {
+			defer func() { <-sem }()
+			defer wg.Done()
+			responses[i], errs[i] = sc.proxyAppConn.CheckTxSync(abci.RequestCheckTx{Tx: tx})
+			if errs[i] != nil || responses[i].Code != abci.CodeTypeOK {
+				atomic.StoreInt32(&rejected, 1)
+			}
+		}(i, tx)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
+}
+
+// ValidateBundle runs the checks AddTx makes on a bundle's txs, without a
+// sidecar: txs[i] is submitted with infos[i], in the given order, with orders
+// counting from config.BundleOrderBase. It returns
+// the error the first failing tx would get, or nil if the bundle would
+// complete. A different tx at an order already taken, which AddTx ignores,
+// and a bundle missing orders are reported as ErrTxMalformedForBundle.
+// Checks that depend on the sidecar's state, like the auction height, other
+// bundles or CheckTx, aren't run.
+func ValidateBundle(txs types.Txs, infos []TxInfo, config *cfg.SidecarConfig) error {
+	if config.BundleOrderBase != 0 {
+		based := make([]TxInfo, len(infos))
+		for i, info := range infos {
+			info.BundleOrder -= int64(config.BundleOrderBase)
+			based[i] = info
+		}
+		infos = based
+	}
+	malformed := func(info TxInfo) error {
+		return ErrTxMalformedForBundle{
+			info.BundleId,
+			info.BundleSize,
+			info.DesiredHeight,
+			info.BundleOrder,
+		}
+	}
+	if len(infos) == 0 || len(txs) != len(infos) {
+		var info TxInfo
+		if len(infos) > 0 {
+			info = infos[0]
+		}
+		return malformed(info)
+	}
+
+	first := infos[0]
+	seen := make(map[[TxKeySize]byte]bool, len(txs))
+	orders := make(map[int64]types.Tx, len(txs))
+	var buffered, bufferedBytes int64
+	for i, tx := range txs {
+		info := infos[i]
+		// txs of another bundle
+		if info.DesiredHeight != first.DesiredHeight || info.BundleId != first.BundleId ||
+			(config.NamespaceBundlesBySender && info.SenderID != first.SenderID) {
+			return malformed(info)
+		}
+		if info.SenderID != first.SenderID {
+			return ErrBundleSenderMismatch{info.BundleId, info.DesiredHeight, info.SenderID, first.SenderID}
+		}
+		if seen[TxKey(tx)] {
+			return ErrTxInCache
+		}
+		seen[TxKey(tx)] = true
+		if info.BundleOrder >= info.BundleSize {
+			return ErrBundleOrderBeyondSize{info.BundleId, info.DesiredHeight, info.BundleSize, info.BundleOrder}
+		}
+		if info.BundleOrder >= first.BundleSize {
+			return ErrBundleOrderBeyondSize{info.BundleId, info.DesiredHeight, first.BundleSize, info.BundleOrder}
+		}
+		if info.BundleOrder < 0 || info.BundleSize != first.BundleSize {
+			return malformed(info)
+		}
+		if int64(len(orders)) >= first.BundleSize {
+			return ErrBundleFull{
+				info.BundleId,
+				info.BundleSize,
+			}
+		}
+		if _, ok := orders[info.BundleOrder]; ok {
+			return malformed(info)
+		}
+		orders[info.BundleOrder] = tx
+
+		if int64(len(orders)) == first.BundleSize {
+			continue
+		}
+		// still incomplete, so subject to eviction
+		buffered++
+		bufferedBytes += int64(len(tx))
+		if config.MaxBufferedOrders > 0 && buffered > int64(config.MaxBufferedOrders) {
+			return ErrBundleOverLimit{info.BundleId, info.DesiredHeight, "max_buffered_orders", int64(config.MaxBufferedOrders), buffered}
+		}
+		if config.SoftMaxTxsBytes > 0 && bufferedBytes > config.SoftMaxTxsBytes {
+			return ErrBundleOverLimit{info.BundleId, info.DesiredHeight, "soft_max_txs_bytes", config.SoftMaxTxsBytes, bufferedBytes}
+		}
+	}
+
+	if int64(len(orders)) == first.BundleSize {
+		return nil
+	}
+	// report the first missing order
+	missing := first
+	for missing.BundleOrder = 0; ; missing.BundleOrder++ {
+		if _, ok := orders[missing.BundleOrder]; !ok {
+			return malformed(missing)
+		}
+	}
+}
+
 // TxsWaitChan returns a channel to wait on transactions. It will be closed
 // once the sidecar is not empty (ie. the internal `mem.txs` has at least one
 // element)
@@ -280,6 +1059,25 @@ func (sc *CListPriorityTxSidecar) Update(
 	deliverTxResponses []*abci.ResponseDeliverTx,
 ) error {
 
+	if len(txs) != len(deliverTxResponses) {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: on sidecar Update(), rejecting update to height %d with %d responses for %d txs", height, len(deliverTxResponses), len(txs)))
+		return ErrResponseLengthMismatch{
+			len(txs),
+			len(deliverTxResponses),
+		}
+	}
+
+	if height < sc.height && !sc.config.AllowReorgUpdates {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: on sidecar Update(), rejecting update to height %d, lower than last updated height %d", height, sc.height))
+		return ErrNonMonotonicUpdate{
+			height,
+			sc.height,
+		}
+	}
+
+	sc.observeAuctionToCommit(height)
+	defer sc.publishHeightStats(height)
+
 	// Set height for block last updated to (i.e. block last committed)
 	sc.height = height
 	sc.notifiedTxsAvailable = false
@@ -297,13 +1095,34 @@ func (sc *CListPriorityTxSidecar) Update(
 		}
 	}
 
+	// reaped bundles whose txs didn't all make it into the block are evicted below
+	// along with everything else for this height, unless we're asked to requeue them
+	if sc.config.UncommittedBundlePolicy == cfg.SidecarBundlePolicyRequeue {
+		sc.requeueUncommittedBundles(height)
+	}
+
+	// complete bundles that may still be included after this height move on
+	sc.carryOverBundles(height)
+
+	// bundles for this height only missing their final order get a grace
+	// period to receive it, instead of being evicted below
+	late := sc.holdLateBundles(height)
+
 	// TODO: cache reset correct?
 	sc.cache.Reset()
-	sc.maxBundleId = 0
+	atomic.StoreInt64(&sc.maxBundleId, 0)
+	// keep track of any requeued bundles for the next reap
+	sc.bundles.Range(func(_, value interface{}) bool {
+		sc.raiseMaxBundleId(value.(*Bundle).bundleId)
+		return true
+	})
 
 	// remove from txs list and txmap
 	for e := sc.txs.Front(); e != nil; e = e.Next() {
 		scTx := e.Value.(*SidecarTx)
+		if _, ok := late[Key{scTx.desiredHeight, scTx.bundleId, scTx.bundleSender}]; ok {
+			continue
+		}
 		if scTx.desiredHeight <= height {
 			fmt.Println(fmt.Sprintf("[mev-tendermint]: on sidecar Update(), found UNCOMMITTED tx %.20q in sidecar, removing! height for tx is %d, and updating to height %d", scTx.tx, scTx.desiredHeight, height))
 			tx := scTx.tx
@@ -313,6 +1132,9 @@ func (sc *CListPriorityTxSidecar) Update(
 
 	// remove the bundles
 	sc.bundles.Range(func(key, _ interface{}) bool {
+		if _, ok := late[key.(Key)]; ok {
+			return true
+		}
 		if bundle, ok := sc.bundles.Load(key); ok {
 			bundle := bundle.(*Bundle)
 			if bundle.desiredHeight <= height {
@@ -326,13 +1148,330 @@ func (sc *CListPriorityTxSidecar) Update(
 	return nil
 }
 
+// publishHeightStats publishes a SidecarHeightStats event for height, with
+// what the sidecar did since the last update, and starts counting again for
+// the next one. Bundles reaped more than once only count the first time, and
+// bundles removed along with a committed height don't count as evicted.
+//
+// Lock() must be held by the caller during execution.
+func (sc *CListPriorityTxSidecar) publishHeightStats(height int64) {
+	data := types.EventDataSidecarHeightStats{
+		Height:         height,
+		ReapedBundles:  int(atomic.SwapInt64(&sc.heightReapedBundles, 0)),
+		ReapedBytes:    atomic.SwapInt64(&sc.heightReapedBytes, 0),
+		EvictedBundles: int(atomic.SwapInt64(&sc.heightEvictedBundles, 0)),
+	}
+	if err := sc.eventBus.PublishEventSidecarHeightStats(data); err != nil {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: on sidecar Update(), failed publishing stats event for height %d: %v", height, err))
+	}
+}
+
+// requeueUncommittedBundles moves the txs left over from bundles reaped for
+// a height up to the given one into bundles for the next height, keeping
+// their relative order. A leftover bundle that would clobber a bundle already
+// held for the next height is left behind to be evicted.
+//
+// Lock() must be held by the caller during execution.
+func (sc *CListPriorityTxSidecar) requeueUncommittedBundles(height int64) {
+	sc.bundles.Range(func(key, value interface{}) bool {
+		bundle := value.(*Bundle)
+		if bundle.desiredHeight > height || atomic.LoadInt32(&bundle.reaped) == 0 {
+			return true
+		}
+
+		// collect, in order, the elements of the txs that weren't committed
+		leftovers := make([]*clist.CElement, 0, bundle.enforcedSize)
+		for bundleOrderIter := int64(0); bundleOrderIter < bundle.enforcedSize; bundleOrderIter++ {
+			if scTx, ok := bundle.orderedTxsMap.Load(bundleOrderIter); ok {
+				if e, ok := sc.txsMap.Load(TxKey(scTx.(*SidecarTx).tx)); ok {
+					leftovers = append(leftovers, e.(*clist.CElement))
+				}
+			}
+		}
+		if len(leftovers) == 0 {
+			return true
+		}
+
+		if !sc.requeueBundle(bundle, leftovers, height+1) {
+			fmt.Println(fmt.Sprintf("[mev-tendermint]: on sidecar Update(), can't requeue bundle with id %d to height %d, already have one there, evicting!", bundle.bundleId, height+1))
+			return true
+		}
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: on sidecar Update(), requeued %d uncommitted txs of bundle with id %d to height %d", len(leftovers), bundle.bundleId, height+1))
+		return true
+	})
+}
+
+// carryOverBundles moves the complete bundles for height whose MaxHeight is
+// past it to the next height, so they're reaped again. Bundles that had some
+// of their txs committed, or were already requeued, are left behind to be
+// evicted, as are bundles still missing orders.
+//
+// Lock() must be held by the caller during execution.
+func (sc *CListPriorityTxSidecar) carryOverBundles(height int64) {
+	sc.bundles.Range(func(key, value interface{}) bool {
+		bundle := value.(*Bundle)
+		if bundle.desiredHeight != height || bundle.maxHeight <= height ||
+			!bundle.isComplete() {
+			return true
+		}
+
+		elems := make([]*clist.CElement, 0, bundle.enforcedSize)
+		for bundleOrderIter := int64(0); bundleOrderIter < bundle.enforcedSize; bundleOrderIter++ {
+			if scTx, ok := bundle.orderedTxsMap.Load(bundleOrderIter); ok {
+				// the tx may have been committed, or replaced by a requeue
+				if e, ok := sc.txsMap.Load(TxKey(scTx.(*SidecarTx).tx)); ok && e.(*clist.CElement).Value == scTx {
+					elems = append(elems, e.(*clist.CElement))
+				}
+			}
+		}
+		if int64(len(elems)) != bundle.enforcedSize {
+			return true
+		}
+
+		if !sc.requeueBundle(bundle, elems, height+1) {
+			fmt.Println(fmt.Sprintf("[mev-tendermint]: on sidecar Update(), can't carry bundle with id %d over to height %d, already have one there, evicting!", bundle.bundleId, height+1))
+			return true
+		}
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: on sidecar Update(), carried bundle with id %d over to height %d, valid up to height %d", bundle.bundleId, height+1, bundle.maxHeight))
+		return true
+	})
+}
+
+// holdLateBundles marks the bundles for height that are only missing their
+// final order as late, returning their keys. Until LateOrderGrace elapses,
+// AddTx accepts that order, moving the then complete bundle to the next height.
+// Lock() must be held by the caller during execution.
+func (sc *CListPriorityTxSidecar) holdLateBundles(height int64) map[Key]struct{} {
+	late := make(map[Key]struct{})
+	if sc.config.LateOrderGrace <= 0 {
+		return late
+	}
+	deadline := sc.now().Add(sc.config.LateOrderGrace)
+	sc.bundles.Range(func(key, value interface{}) bool {
+		bundle := value.(*Bundle)
+		if bundle.desiredHeight != height || atomic.LoadInt64(&bundle.currSize) != bundle.enforcedSize-1 {
+			return true
+		}
+		// skip bundles that had some of their txs committed
+		complete := true
+		bundle.orderedTxsMap.Range(func(_, scTx interface{}) bool {
+			_, complete = sc.txsMap.Load(TxKey(scTx.(*SidecarTx).tx))
+			return complete
+		})
+		if !complete {
+			return true
+		}
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: on sidecar Update(), holding bundle with id %d at height %d for its final order", bundle.bundleId, height))
+		bundle.lateDeadline = deadline
+		late[key.(Key)] = struct{}{}
+		return true
+	})
+	return late
+}
+
+// acceptsLateOrder returns true if txInfo is for a late bundle of the height
+// just sealed that is still within its grace period.
+func (sc *CListPriorityTxSidecar) acceptsLateOrder(txInfo TxInfo) bool {
+	// the completed bundle would land at the auction height
+	if txInfo.DesiredHeight != sc.height || sc.auctionHeightClosed() {
+		return false
+	}
+	bundle, ok := sc.bundles.Load(sc.bundleKey(txInfo))
+	if !ok {
+		return false
+	}
+	lateDeadline := bundle.(*Bundle).lateDeadline
+	return !lateDeadline.IsZero() && sc.now().Before(lateDeadline)
+}
+
+// auctionHeightClosed returns true if the auction height was sealed, or if
+// the current height policy rejects orders for the auction height, and
+// reaping for it already started.
+func (sc *CListPriorityTxSidecar) auctionHeightClosed() bool {
+	if atomic.LoadInt64(&sc.sealedHeight) == sc.heightForFiringAuction {
+		return true
+	}
+	return sc.config.CurrentHeightPolicy == cfg.SidecarCurrentHeightReject &&
+		atomic.LoadInt64(&sc.reapedHeight) == sc.heightForFiringAuction
+}
+
+// promoteLateBundle moves a late bundle that just received its final order to
+// the next height, so it makes the next reap.
+func (sc *CListPriorityTxSidecar) promoteLateBundle(bundle *Bundle) *Bundle {
+	key := bundle.key()
+	elems := make([]*clist.CElement, 0, bundle.enforcedSize)
+	for bundleOrderIter := int64(0); bundleOrderIter < bundle.enforcedSize; bundleOrderIter++ {
+		if scTx, ok := bundle.orderedTxsMap.Load(bundleOrderIter); ok {
+			if e, ok := sc.txsMap.Load(TxKey(scTx.(*SidecarTx).tx)); ok {
+				elems = append(elems, e.(*clist.CElement))
+			}
+		}
+	}
+	if int64(len(elems)) != bundle.enforcedSize || !sc.requeueBundle(bundle, elems, bundle.desiredHeight+1) {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx(): can't move late bundle with id %d to height %d, evicting!", bundle.bundleId, bundle.desiredHeight+1))
+		sc.removeBundle(key, bundle)
+		return nil
+	}
+	fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx(): late bundle with id %d got its final order, moved to height %d", bundle.bundleId, bundle.desiredHeight+1))
+	sc.bundles.Delete(key)
+	promoted, _ := sc.bundles.Load(Key{bundle.desiredHeight + 1, bundle.bundleId, bundle.sender})
+	return promoted.(*Bundle)
+}
+
+// Requeue moves the bundle with bundleID at desiredHeight, which must have
+// been reaped, to newDesiredHeight, e.g. after the proposal it was reaped for
+// was rejected, so it's reaped again there rather than evicted. It returns
+// ErrBundleExists if a bundle with the same id is already held at
+// newDesiredHeight, and ErrWrongHeight if that height's auction already fired.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) Requeue(bundleID, desiredHeight, newDesiredHeight int64) error {
+	sc.updateMtx.Lock()
+	defer sc.updateMtx.Unlock()
+
+	if newDesiredHeight < sc.heightForFiringAuction {
+		return ErrWrongHeight{
+			int(newDesiredHeight),
+			int(sc.heightForFiringAuction),
+		}
+	}
+	bundle, ok := sc.loadBundle(desiredHeight, bundleID)
+	if !ok || atomic.LoadInt32(&bundle.reaped) == 0 {
+		return ErrBundleNotReaped{bundleID, desiredHeight}
+	}
+	if newDesiredHeight == desiredHeight {
+		return nil
+	}
+
+	elems := make([]*clist.CElement, 0, bundle.enforcedSize)
+	for bundleOrderIter := int64(0); bundleOrderIter < bundle.enforcedSize; bundleOrderIter++ {
+		if scTx, ok := bundle.orderedTxsMap.Load(bundleOrderIter); ok {
+			if e, ok := sc.txsMap.Load(TxKey(scTx.(*SidecarTx).tx)); ok {
+				elems = append(elems, e.(*clist.CElement))
+			}
+		}
+	}
+	if int64(len(elems)) != bundle.enforcedSize {
+		return ErrBundleNotReaped{bundleID, desiredHeight}
+	}
+	if !sc.requeueBundle(bundle, elems, newDesiredHeight) {
+		return ErrBundleExists{bundleID, newDesiredHeight}
+	}
+	sc.bundles.Delete(bundle.key())
+	sc.raiseMaxBundleId(bundleID)
+	fmt.Println(fmt.Sprintf("[mev-tendermint]: Requeue(): moved reaped bundle with id %d from height %d to height %d", bundleID, desiredHeight, newDesiredHeight))
+	return nil
+}
+
+// OnPeerDisconnect evicts the incomplete bundles sent by the peer with
+// senderID, which may never complete now that it's gone. Complete bundles
+// are kept, as are incomplete bundles with pinned txs, unless
+// SidecarConfig.PinnedBundlePolicy is "evict". Bundles are attributed to the
+// peer that sent their first order.
+//
+// Safe for concurrent use by multiple goroutines, it holds the lock while it
+// runs.
+func (sc *CListPriorityTxSidecar) OnPeerDisconnect(senderID uint16) {
+	sc.updateMtx.Lock()
+	defer sc.updateMtx.Unlock()
+
+	keepPinned := sc.config.PinnedBundlePolicy != cfg.SidecarPinnedBundleEvict
+	sc.bundles.Range(func(key, value interface{}) bool {
+		bundle := value.(*Bundle)
+		if bundle.senderID != senderID || bundle.isComplete() {
+			return true
+		}
+		if keepPinned && atomic.LoadInt32(&bundle.pinned) == 1 {
+			return true
+		}
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: OnPeerDisconnect(): evicting incomplete bundle with id %d at height %d from peer %d", bundle.bundleId, bundle.desiredHeight, senderID))
+		sc.evictBundle(key, bundle)
+		return true
+	})
+}
+
+// RemoveBundlesForHeight drops every bundle for height, complete or not,
+// along with their txs, returning how many bundles were removed. Their txs are
+// also removed from the cache, so they can be resubmitted.
+//
+// Safe for concurrent use by multiple goroutines, it holds the lock while it
+// runs.
+func (sc *CListPriorityTxSidecar) RemoveBundlesForHeight(height int64) int {
+	sc.updateMtx.Lock()
+	defer sc.updateMtx.Unlock()
+
+	removed := 0
+	for _, key := range sc.bundleKeys(height) {
+		if bundle, ok := sc.bundles.Load(key); ok {
+			sc.removeBundle(key, bundle.(*Bundle))
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0
+	}
+	fmt.Println(fmt.Sprintf("[mev-tendermint]: RemoveBundlesForHeight(): removed %d bundles at height %d", removed, height))
+	atomic.StoreInt64(&sc.maxBundleId, 0)
+	sc.bundles.Range(func(_, value interface{}) bool {
+		sc.raiseMaxBundleId(value.(*Bundle).bundleId)
+		return true
+	})
+	return removed
+}
+
+// requeueBundle moves the txs in elems, in order, into a new bundle with the
+// same id at height. It returns false, leaving the txs alone, if there's
+// already a bundle with that id at height.
+func (sc *CListPriorityTxSidecar) requeueBundle(bundle *Bundle, elems []*clist.CElement, height int64) bool {
+	requeued := &Bundle{
+		desiredHeight:   height,
+		maxHeight:       bundle.maxHeight,
+		bundleId:        bundle.bundleId,
+		sender:          bundle.sender,
+		currSize:        int64(len(elems)),
+		enforcedSize:    int64(len(elems)),
+		minOrdersToReap: bundle.minOrdersToReap,
+		gasWanted:       bundle.gasWanted,
+		orderedTxsMap:   &sync.Map{},
+		senderID:        bundle.senderID,
+		// a late bundle is still waiting on admission, a requeued one was admitted
+		pendingAdmission: atomic.LoadInt32(&bundle.pendingAdmission),
+		pinned:           atomic.LoadInt32(&bundle.pinned),
+	}
+	if _, loaded := sc.bundles.LoadOrStore(requeued.key(), requeued); loaded {
+		return false
+	}
+
+	for i, e := range elems {
+		oldTx := e.Value.(*SidecarTx)
+		// replace the element rather than mutate it, since broadcast routines
+		// read it without holding the lock, this also re-gossips it for the new height
+		sc.removeTx(oldTx.tx, e, false)
+		scTx := &SidecarTx{
+			desiredHeight: height,
+			tx:            oldTx.tx,
+			bundleId:      bundle.bundleId,
+			bundleSender:  bundle.sender,
+			bundleOrder:   int64(i),
+			bundleSize:    requeued.enforcedSize,
+			execHint:      oldTx.execHint,
+			gasWanted:     oldTx.gasWanted,
+		}
+		requeued.orderedTxsMap.Store(scTx.bundleOrder, scTx)
+		newElem := sc.txs.PushBack(scTx)
+		sc.txsMap.Store(TxKey(scTx.tx), newElem)
+		atomic.AddInt64(&sc.txsBytes, int64(len(scTx.tx)))
+	}
+	return true
+}
+
 // Lock() must be help by the caller during execution.
 // Lock() must be help by the caller during execution.
 func (sc *CListPriorityTxSidecar) Flush() {
 	sc.cache.Reset()
 
 	sc.notifiedTxsAvailable = false
-	sc.maxBundleId = 0
+	atomic.StoreInt64(&sc.maxBundleId, 0)
 
 	_ = atomic.SwapInt64(&sc.txsBytes, 0)
 
@@ -347,10 +1486,223 @@ func (sc *CListPriorityTxSidecar) Flush() {
 	})
 
 	// TODO: does the below not have garbage collection?
-	sc.bundles.Range(func(key, _ interface{}) bool {
+	flushed := make([]*Bundle, 0)
+	sc.bundles.Range(func(key, value interface{}) bool {
 		sc.bundles.Delete(key)
+		flushed = append(flushed, value.(*Bundle))
 		return true
 	})
+
+	if sc.evictionHook == nil {
+		return
+	}
+	sort.Slice(flushed, func(i, j int) bool {
+		a, b := flushed[i].key(), flushed[j].key()
+		if a.height != b.height {
+			return a.height < b.height
+		}
+		if a.bundleId != b.bundleId {
+			return a.bundleId < b.bundleId
+		}
+		return a.sender < b.sender
+	})
+	for _, bundle := range flushed {
+		sc.evictionHook(bundleMeta(bundle))
+	}
+}
+
+// evictIncompleteBundlesOverLimit evicts the incomplete bundles that least
+// recently received an order until the number of txs buffered across all
+// incomplete bundles is back within MaxBufferedOrders.
+//
+// Called from AddTx, with the read lock held.
+func (sc *CListPriorityTxSidecar) evictIncompleteBundlesOverLimit() {
+	for {
+		buffered, lruKey, lruBundle := sc.lruIncompleteBundle()
+		if buffered <= int64(sc.config.MaxBufferedOrders) {
+			return
+		}
+		if lruBundle == nil {
+			fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx(): WARNING %d buffered orders is over the limit of %d, but only bundles with pinned txs are left, keeping them", buffered, sc.config.MaxBufferedOrders))
+			return
+		}
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx(): %d buffered orders is over the limit of %d, evicting incomplete bundle with id %d at height %d", buffered, sc.config.MaxBufferedOrders, lruBundle.bundleId, lruBundle.desiredHeight))
+		sc.evictBundle(lruKey, lruBundle)
+	}
+}
+
+// evictIncompleteBundlesOverSoftMaxBytes evicts the incomplete bundles that
+// least recently received an order until the sidecar's txs are back within
+// SoftMaxTxsBytes. Complete bundles are never evicted.
+//
+// Called from AddTx, with the read lock held.
+func (sc *CListPriorityTxSidecar) evictIncompleteBundlesOverSoftMaxBytes() {
+	for {
+		txsBytes := sc.TxsBytes()
+		if txsBytes <= sc.config.SoftMaxTxsBytes {
+			return
+		}
+		_, lruKey, lruBundle := sc.lruIncompleteBundle()
+		if lruBundle == nil {
+			if sc.hasPinnedIncompleteBundle() {
+				fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx(): WARNING %d bytes of txs is over the soft limit of %d, but only bundles with pinned txs are left, keeping them", txsBytes, sc.config.SoftMaxTxsBytes))
+			}
+			return
+		}
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx(): %d bytes of txs is over the soft limit of %d, evicting incomplete bundle with id %d at height %d", txsBytes, sc.config.SoftMaxTxsBytes, lruBundle.bundleId, lruBundle.desiredHeight))
+		if sc.evictBundle(lruKey, lruBundle) {
+			sc.metrics.SoftLimitEvictedSidecarBundles.Add(1)
+		}
+	}
+}
+
+// lruIncompleteBundle returns the incomplete bundle that least recently
+// received an order, if any, along with the number of txs buffered across all
+// incomplete bundles. Bundles with pinned txs are only returned if
+// PinnedBundlePolicy evicts them.
+func (sc *CListPriorityTxSidecar) lruIncompleteBundle() (buffered int64, lruKey interface{}, lruBundle *Bundle) {
+	keepPinned := sc.config.PinnedBundlePolicy != cfg.SidecarPinnedBundleEvict
+	var lruProgress int64
+	sc.bundles.Range(func(key, value interface{}) bool {
+		bundle := value.(*Bundle)
+		currSize := atomic.LoadInt64(&bundle.currSize)
+		if currSize >= bundle.enforcedSize {
+			return true
+		}
+		buffered += currSize
+		if keepPinned && atomic.LoadInt32(&bundle.pinned) == 1 {
+			return true
+		}
+		if progress := atomic.LoadInt64(&bundle.lastProgress); lruBundle == nil || progress < lruProgress {
+			lruKey, lruBundle, lruProgress = key, bundle, progress
+		}
+		return true
+	})
+	return buffered, lruKey, lruBundle
+}
+
+// hasPinnedIncompleteBundle returns true if an incomplete bundle has a pinned tx.
+func (sc *CListPriorityTxSidecar) hasPinnedIncompleteBundle() bool {
+	found := false
+	sc.bundles.Range(func(_, value interface{}) bool {
+		bundle := value.(*Bundle)
+		found = !bundle.isComplete() && atomic.LoadInt32(&bundle.pinned) == 1
+		return !found
+	})
+	return found
+}
+
+// evictBundle removes an incomplete bundle to get back under the sidecar's
+// limits, counting it if it has pinned txs. It returns false if another
+// caller evicted the bundle first, see removeBundle.
+func (sc *CListPriorityTxSidecar) evictBundle(key interface{}, bundle *Bundle) bool {
+	if !sc.removeBundle(key, bundle) {
+		return false
+	}
+	if atomic.LoadInt32(&bundle.pinned) == 1 {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: AddTx(): WARNING evicted bundle with id %d at height %d, which had pinned txs", bundle.bundleId, bundle.desiredHeight))
+		sc.metrics.PinnedEvictedSidecarBundles.Add(1)
+	}
+	return true
+}
+
+// removeBundle drops the bundle and all of its txs from the sidecar. Its txs
+// are also removed from the cache, so they can be resubmitted.
+//
+// It can run under the read lock, so the bundle is claimed first: only the
+// caller that takes it out of the bundles map drops its txs, and it returns
+// false if another caller got there first.
+func (sc *CListPriorityTxSidecar) removeBundle(key interface{}, bundle *Bundle) bool {
+	value, loaded := sc.bundles.LoadAndDelete(key)
+	if !loaded {
+		return false
+	}
+	if value.(*Bundle) != bundle {
+		// a newer bundle took the key, put it back
+		sc.bundles.LoadOrStore(key, value)
+		return false
+	}
+	sc.updatePeerStats(bundle.senderID, func(stats *PeerStats) { stats.EvictedBundles++ })
+	atomic.AddInt64(&sc.heightEvictedBundles, 1)
+	bundle.orderedTxsMap.Range(func(_, value interface{}) bool {
+		tx := value.(*SidecarTx).tx
+		if e, ok := sc.txsMap.Load(TxKey(tx)); ok {
+			sc.removeTx(tx, e.(*clist.CElement), true)
+		}
+		return true
+	})
+	if sc.evictionHook != nil {
+		sc.evictionHook(bundleMeta(bundle))
+	}
+	return true
+}
+
+// bundleMeta returns the description of bundle passed to hooks.
+func bundleMeta(bundle *Bundle) BundleMeta {
+	return BundleMeta{
+		DesiredHeight: bundle.desiredHeight,
+		BundleId:      bundle.bundleId,
+		SenderID:      bundle.senderID,
+	}
+}
+
+// CheckInvariants checks the sidecar's bookkeeping is consistent: every tx
+// in the list is indexed and held by its bundle at its order, the byte count
+// matches the txs, and every bundle's size matches the orders it holds. It
+// returns an error describing the first inconsistency found, if any.
+//
+// Safe for concurrent use by multiple goroutines, it holds the lock while it
+// runs.
+func (sc *CListPriorityTxSidecar) CheckInvariants() error {
+	sc.updateMtx.Lock()
+	defer sc.updateMtx.Unlock()
+
+	var numTxs int
+	var txsBytes int64
+	for e := sc.txs.Front(); e != nil; e = e.Next() {
+		scTx := e.Value.(*SidecarTx)
+		numTxs++
+		txsBytes += int64(len(scTx.tx))
+		if indexed, ok := sc.txsMap.Load(TxKey(scTx.tx)); !ok || indexed.(*clist.CElement) != e {
+			return fmt.Errorf("tx %X isn't indexed", TxKey(scTx.tx))
+		}
+		bundle, ok := sc.bundles.Load(Key{scTx.desiredHeight, scTx.bundleId, scTx.bundleSender})
+		if !ok {
+			return fmt.Errorf("tx %X has no bundle with id %d at height %d", TxKey(scTx.tx), scTx.bundleId, scTx.desiredHeight)
+		}
+		if held, ok := bundle.(*Bundle).orderedTxsMap.Load(scTx.bundleOrder); !ok || held.(*SidecarTx) != scTx {
+			return fmt.Errorf("tx %X isn't held by bundle with id %d at height %d at order %d", TxKey(scTx.tx), scTx.bundleId, scTx.desiredHeight, scTx.bundleOrder)
+		}
+	}
+	if numTxs != sc.txs.Len() {
+		return fmt.Errorf("%d txs in the list, but its length is %d", numTxs, sc.txs.Len())
+	}
+	var numIndexed int
+	sc.txsMap.Range(func(_, _ interface{}) bool {
+		numIndexed++
+		return true
+	})
+	if numIndexed != numTxs {
+		return fmt.Errorf("%d txs indexed, but %d in the list", numIndexed, numTxs)
+	}
+	if bytes := sc.TxsBytes(); bytes != txsBytes {
+		return fmt.Errorf("txs take %d bytes, but %d are accounted for", txsBytes, bytes)
+	}
+
+	var err error
+	sc.bundles.Range(func(_, value interface{}) bool {
+		bundle := value.(*Bundle)
+		var numOrders int64
+		bundle.orderedTxsMap.Range(func(_, _ interface{}) bool {
+			numOrders++
+			return true
+		})
+		if numOrders != bundle.currSize || bundle.currSize > bundle.enforcedSize {
+			err = fmt.Errorf("bundle with id %d at height %d holds %d orders, but has size %d out of %d", bundle.bundleId, bundle.desiredHeight, numOrders, bundle.currSize, bundle.enforcedSize)
+		}
+		return err == nil
+	})
+	return err
 }
 
 // Safe for concurrent use by multiple goroutines.
@@ -358,6 +1710,12 @@ func (sc *CListPriorityTxSidecar) Size() int {
 	return sc.txs.Len()
 }
 
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) HasTx(txKey [TxKeySize]byte) bool {
+	_, ok := sc.txsMap.Load(txKey)
+	return ok
+}
+
 // Safe for concurrent use by multiple goroutines.
 func (sc *CListPriorityTxSidecar) NumBundles() int {
 	i := 0
@@ -368,9 +1726,23 @@ func (sc *CListPriorityTxSidecar) NumBundles() int {
 	return i
 }
 
+// raiseMaxBundleId raises maxBundleId to bundleId, returning true if it
+// wasn't already at least that.
+func (sc *CListPriorityTxSidecar) raiseMaxBundleId(bundleId int64) bool {
+	for {
+		maxBundleId := atomic.LoadInt64(&sc.maxBundleId)
+		if bundleId < maxBundleId {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&sc.maxBundleId, maxBundleId, bundleId) {
+			return true
+		}
+	}
+}
+
 // Safe for concurrent use by multiple goroutines.
 func (sc *CListPriorityTxSidecar) MaxBundleId() int64 {
-	return sc.maxBundleId
+	return atomic.LoadInt64(&sc.maxBundleId)
 }
 
 func (sc *CListPriorityTxSidecar) HeightForFiringAuction() int64 {
@@ -379,8 +1751,7 @@ func (sc *CListPriorityTxSidecar) HeightForFiringAuction() int64 {
 
 // Safe for concurrent use by multiple goroutines.
 func (sc *CListPriorityTxSidecar) GetEnforcedBundleSize(bundleId int64) int {
-	if bundle, ok := sc.bundles.Load(Key{sc.heightForFiringAuction, bundleId}); ok {
-		bundle := bundle.(*Bundle)
+	if bundle, ok := sc.loadBundle(sc.heightForFiringAuction, bundleId); ok {
 		return int(bundle.enforcedSize)
 	} else {
 		fmt.Println("Error GetEnforcedBundleSize(): Don't have a bundle for bundleId", bundleId)
@@ -390,20 +1761,164 @@ func (sc *CListPriorityTxSidecar) GetEnforcedBundleSize(bundleId int64) int {
 
 // Safe for concurrent use by multiple goroutines.
 func (sc *CListPriorityTxSidecar) GetCurrBundleSize(bundleId int64) int {
-	if bundle, ok := sc.bundles.Load(Key{sc.heightForFiringAuction, bundleId}); ok {
-		bundle := bundle.(*Bundle)
-		return int(bundle.currSize)
+	if bundle, ok := sc.loadBundle(sc.heightForFiringAuction, bundleId); ok {
+		return int(atomic.LoadInt64(&bundle.currSize))
 	} else {
 		fmt.Println("Error GetBundleSize(): Don't have a bundle for bundleId", bundleId)
 		return 0
 	}
 }
 
+// IsBundleComplete reports whether every order of the bundle with bundleID
+// at desiredHeight has arrived. It returns false if there is no such bundle.
+// A complete bundle may still be held back from reaps while it awaits
+// admission.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) IsBundleComplete(desiredHeight, bundleID int64) bool {
+	bundle, ok := sc.loadBundle(desiredHeight, bundleID)
+	return ok && bundle.isComplete()
+}
+
+// ForceComplete fills the orders the bundle with bundleID at desiredHeight is
+// missing with fillTxs, keyed by order counting from
+// SidecarConfig.BundleOrderBase, so a bundle stuck waiting for them can be
+// reaped. The fill txs are added as if the peer that sent the bundle sent
+// them. Orders the bundle already holds are left as they are. It returns
+// ErrForceComplete, leaving the bundle as it was, unless
+// SidecarConfig.AllowForceComplete is set and fillTxs has every missing
+// order. If adding a fill tx fails, its error is returned as AddTx returned
+// it, and the fill txs added before it are kept.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ForceComplete(desiredHeight, bundleID int64, fillTxs map[int64]types.Tx) error {
+	config := sc.currentConfig()
+	if !config.AllowForceComplete {
+		return ErrForceComplete{bundleID, desiredHeight, "allow_force_complete isn't set"}
+	}
+	bundle, ok := sc.loadBundle(desiredHeight, bundleID)
+	if !ok {
+		return ErrForceComplete{bundleID, desiredHeight, "no such bundle"}
+	}
+
+	orderBase := int64(config.BundleOrderBase)
+	missing := make([]int64, 0)
+	for bundleOrder := int64(0); bundleOrder < bundle.enforcedSize; bundleOrder++ {
+		if _, ok := bundle.orderedTxsMap.Load(bundleOrder); ok {
+			continue
+		}
+		if _, ok := fillTxs[bundleOrder+orderBase]; !ok {
+			return ErrForceComplete{bundleID, desiredHeight, fmt.Sprintf("no fill tx for missing order %d", bundleOrder+orderBase)}
+		}
+		missing = append(missing, bundleOrder)
+	}
+
+	for _, bundleOrder := range missing {
+		txInfo := TxInfo{
+			SenderID:      bundle.senderID,
+			BundleId:      bundleID,
+			DesiredHeight: desiredHeight,
+			BundleOrder:   bundleOrder + orderBase,
+			BundleSize:    bundle.enforcedSize,
+		}
+		if err := sc.AddTx(fillTxs[bundleOrder+orderBase], txInfo); err != nil {
+			return err
+		}
+	}
+	fmt.Println(fmt.Sprintf("[mev-tendermint]: ForceComplete(): filled %d missing orders of bundle with id %d at height %d", len(missing), bundleID, desiredHeight))
+	return nil
+}
+
 // Safe for concurrent use by multiple goroutines.
 func (sc *CListPriorityTxSidecar) TxsBytes() int64 {
 	return atomic.LoadInt64(&sc.txsBytes)
 }
 
+// Config returns a copy of the configuration the sidecar currently uses, the
+// one it was created with unless UpdateConfig swapped it.
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) Config() cfg.SidecarConfig {
+	return *sc.currentConfig()
+}
+
+// currentConfig returns the sidecar's configuration, for callers that don't
+// hold the lock, see UpdateConfig.
+func (sc *CListPriorityTxSidecar) currentConfig() *cfg.SidecarConfig {
+	sc.configMtx.RLock()
+	defer sc.configMtx.RUnlock()
+	return sc.config
+}
+
+// UpdateConfig swaps the sidecar's configuration for config, e.g. to tune its
+// limits without a restart. config must pass SidecarConfig.Validate, and
+// mustn't change the settings the sidecar is built around:
+// namespace_bundles_by_sender, metrics_label, max_concurrent_bundle_checks,
+// bundle_order_base, which peers encode the orders they gossip with, and the
+// bundle export ones. Tightened limits apply to txs added from then
+// on. Bundles already held are kept, unless
+// config.EvictOverLimitsOnConfigUpdate is set, in which case incomplete
+// bundles are evicted until back within max_buffered_orders and
+// soft_max_txs_bytes, as when an order is added.
+//
+// Safe for concurrent use by multiple goroutines, it holds the lock while it
+// runs.
+func (sc *CListPriorityTxSidecar) UpdateConfig(config cfg.SidecarConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	sc.updateMtx.Lock()
+	defer sc.updateMtx.Unlock()
+
+	current := sc.config
+	fixed := []struct {
+		name    string
+		changed bool
+	}{
+		{"namespace_bundles_by_sender", config.NamespaceBundlesBySender != current.NamespaceBundlesBySender},
+		{"metrics_label", config.MetricsLabel != current.MetricsLabel},
+		{"max_concurrent_bundle_checks", config.MaxConcurrentBundleChecks != current.MaxConcurrentBundleChecks},
+		{"bundle_order_base", config.BundleOrderBase != current.BundleOrderBase},
+		{"bundle_export_path", config.BundleExportPath != current.BundleExportPath},
+		{"bundle_export_max_file_size", config.BundleExportMaxFileSize != current.BundleExportMaxFileSize},
+	}
+	for _, setting := range fixed {
+		if setting.changed {
+			return ErrSidecarSettingFixed{setting.name}
+		}
+	}
+
+	sc.configMtx.Lock()
+	sc.config = &config
+	sc.configMtx.Unlock()
+	fmt.Println("[mev-tendermint]: UpdateConfig(): updated the sidecar config")
+
+	if !config.EvictOverLimitsOnConfigUpdate {
+		return nil
+	}
+	if config.MaxBufferedOrders > 0 {
+		sc.evictIncompleteBundlesOverLimit()
+	}
+	if config.SoftMaxTxsBytes > 0 {
+		sc.evictIncompleteBundlesOverSoftMaxBytes()
+	}
+	return nil
+}
+
+// MaxBufferedOrders returns the limit on txs held across incomplete bundles
+// (0 - unlimited).
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) MaxBufferedOrders() int {
+	return sc.currentConfig().MaxBufferedOrders
+}
+
+// ReapGracePeriod returns how long ReapMaxTxsWithDeadline waits for
+// incomplete bundles.
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapGracePeriod() time.Duration {
+	return sc.currentConfig().ReapGracePeriod
+}
+
 // Called from:
 //  - FlushSidecar (lock held) if tx was committed
 func (sc *CListPriorityTxSidecar) removeTx(tx types.Tx, elem *clist.CElement, removeFromCache bool) {
@@ -418,54 +1933,789 @@ func (sc *CListPriorityTxSidecar) removeTx(tx types.Tx, elem *clist.CElement, re
 }
 
 // Safe for concurrent use by multiple goroutines.
-// TODO: add gas and byte limits (but requires tracking gas)
+// TODO: gas limits require tracking gas, which is always 0 for now
 
-// this reap function iterates over all the bundleIds up to maxBundleId
+// this reap function iterates over all the bundles for the auction height by bundleId
 // ... then goes over each bundle via the bundleOrders (up to enforcedSize for bundle)
 // ... and reaps them in this order
+// CONTRACT: the order is deterministic: by ascending bundleId (then sender, if
+// namespaced, see Key), then ascending bundleOrder,
+// regardless of the order txs arrived in (see TestSidecarReapOrdering)
 func (sc *CListPriorityTxSidecar) ReapMaxTxs() []*MempoolTx {
+	return sc.ReapMaxTxsInto(make([]*MempoolTx, 0, sc.txs.Len()))
+}
+
+// ReapMaxTxsInto reaps the same txs as ReapMaxTxs, but appends them to buf[:0]
+// so callers reaping at a high frequency can reuse the backing array across calls.
+// The returned slice must be used in place of buf, as it may have been regrown.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapMaxTxsInto(buf []*MempoolTx) []*MempoolTx {
+	memTxs, _, _ := sc.reapMaxBytesMaxGasInto(buf, -1, -1, nil)
+	return memTxs
+}
+
+// ReapMaxBytesMaxGas reaps bundles in the same order as ReapMaxTxs, as long
+// as they fit in a total of maxBytes bytes and maxGas gas. Bundles are only
+// ever reaped whole: a bundle that doesn't fit in what's left of the budget is
+// skipped, and smaller bundles after it may still be reaped.
+// If both maxes are negative, there is no cap on the size of all returned
+// transactions (~ all available transactions). A negative max means that
+// resource is unlimited, while a zero max reaps nothing. Either way, maxBytes
+// is capped to SidecarConfig.MaxReapBytesPerHeight, if set.
+// It also returns the total bytes (as proto encoded in a block) and gas of
+// the reaped txs, so callers don't need to recompute them.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapMaxBytesMaxGas(maxBytes, maxGas int64) (memTxs []*MempoolTx, totalBytes, totalGas int64) {
+	return sc.reapMaxBytesMaxGasInto(make([]*MempoolTx, 0, sc.txs.Len()), maxBytes, maxGas, nil)
+}
+
+// ReapBudget resolves the byte and gas budget of a reap for a block under
+// params: SidecarConfig.ReapBytesFraction of the block's MaxBytes and
+// ReapGasFraction of its MaxGas. An unlimited (negative) MaxGas stays
+// unlimited, and a zero fraction leaves the whole block to the sidecar.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapBudget(params tmproto.ConsensusParams) (maxBytes, maxGas int64) {
+	config := sc.currentConfig()
+	return reapFraction(params.Block.MaxBytes, config.ReapBytesFraction),
+		reapFraction(params.Block.MaxGas, config.ReapGasFraction)
+}
+
+func reapFraction(max int64, fraction float64) int64 {
+	if max < 0 || fraction == 0 {
+		return max
+	}
+	return int64(float64(max) * fraction)
+}
+
+// ReapForConsensusParams reaps like ReapMaxBytesMaxGas, with the budget
+// ReapBudget resolves for params at the time of the reap.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapForConsensusParams(params tmproto.ConsensusParams) (memTxs []*MempoolTx, totalBytes, totalGas int64) {
+	maxBytes, maxGas := sc.ReapBudget(params)
+	return sc.ReapMaxBytesMaxGas(maxBytes, maxGas)
+}
+
+// ReapTxs reaps the same txs as ReapMaxBytesMaxGas, for callers that only
+// need the raw txs.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapTxs(maxBytes, maxGas int64) types.Txs {
+	memTxs, _, _ := sc.ReapMaxBytesMaxGas(maxBytes, maxGas)
+	txs := make(types.Txs, len(memTxs))
+	for i, memTx := range memTxs {
+		txs[i] = memTx.tx
+	}
+	return txs
+}
+
+// ReapBundles reaps the same txs as ReapMaxBytesMaxGas, grouped by bundle:
+// one slice per bundle, in reap order, with its txs in bundle order, for
+// callers executing bundles atomically.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapBundles(maxBytes, maxGas int64) [][]*MempoolTx {
+	bundles := make([][]*MempoolTx, 0)
+	sc.reapMaxBytesMaxGasInto(make([]*MempoolTx, 0, sc.txs.Len()), maxBytes, maxGas, func(_ *Bundle, memTxs []*MempoolTx) {
+		// cap the slice, so appending to one bundle can't overwrite the next
+		bundles = append(bundles, memTxs[:len(memTxs):len(memTxs)])
+	})
+	return bundles
+}
+
+// ReapWithMustInclude reaps like ReapMaxBytesMaxGas, but first takes the
+// bundles with the given ids for the auction height, in the given order, then
+// fills what's left of the budget with the others, in reap order. If one of
+// them isn't complete and ready to reap, or they don't all fit in the budget
+// or MaxBundlesPerReap, it returns an ErrMustIncludeBundle and reaps nothing,
+// without firing the auction. Repeated ids are only included once.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapWithMustInclude(mustIDs []int64, maxBytes, maxGas int64) ([]*MempoolTx, error) {
+	auction := types.EventDataSidecarAuction{}
+	reapedBundles := make([]types.EventDataSidecarBundle, 0)
+
+	sc.updateMtx.RLock()
+	selection, err := sc.selectMustInclude(mustIDs, maxBytes, maxGas)
+	if err != nil {
+		sc.updateMtx.RUnlock()
+		return nil, err
+	}
+	// published once the lock is released, as for the other reaps
+	defer sc.publishReapEvents(&auction, &reapedBundles)
+	defer sc.updateMtx.RUnlock()
+
+	memTxs, _, _ := sc.reapLocked(make([]*MempoolTx, 0, sc.txs.Len()), sc.selectBundlesAfter(selection, maxBytes, maxGas), nil, &auction, &reapedBundles)
+	return memTxs, nil
+}
+
+// selectMustInclude picks the bundles given to ReapWithMustInclude, as the
+// start of its selection.
+//
+// The lock must be held by the caller during execution, at least for reading.
+func (sc *CListPriorityTxSidecar) selectMustInclude(mustIDs []int64, maxBytes, maxGas int64) (reapSelection, error) {
+	var selection reapSelection
+	height := sc.heightForFiringAuction
+	maxBytes = sc.reapBytesBudget(maxBytes)
+
+	selected := make(map[Key]struct{}, len(mustIDs))
+	for _, bundleId := range mustIDs {
+		bundle, ok := sc.loadBundle(height, bundleId)
+		if !ok {
+			return selection, ErrMustIncludeBundle{bundleId, height, "no such bundle"}
+		}
+		key := bundle.key()
+		if _, ok := selected[key]; ok {
+			continue
+		}
+		if !bundle.isComplete() {
+			return selection, ErrMustIncludeBundle{bundleId, height, "bundle is incomplete"}
+		}
+		if atomic.LoadInt32(&bundle.pendingAdmission) == 1 {
+			return selection, ErrMustIncludeBundle{bundleId, height, "bundle is pending admission"}
+		}
+		if sc.reapExcludePeers[bundle.senderID] {
+			return selection, ErrMustIncludeBundle{bundleId, height, fmt.Sprintf("bundle is from excluded peer %d", bundle.senderID)}
+		}
+		if maxBundles := sc.config.MaxBundlesPerReap; maxBundles > 0 && len(selection.bundles) >= maxBundles {
+			return selection, ErrMustIncludeBundle{bundleId, height, fmt.Sprintf("over the max of %d bundles per reap", maxBundles)}
+		}
+
+		txs := make([]*SidecarTx, 0, bundle.enforcedSize)
+		var bundleBytes, bundleGas int64
+		for bundleOrder := int64(0); bundleOrder < bundle.enforcedSize; bundleOrder++ {
+			scTx, ok := bundle.orderedTxsMap.Load(bundleOrder)
+			if !ok {
+				return selection, ErrMustIncludeBundle{bundleId, height, fmt.Sprintf("missing order %d", bundleOrder)}
+			}
+			tx := scTx.(*SidecarTx)
+			txs = append(txs, tx)
+			bundleBytes += types.ComputeProtoSizeForTxs([]types.Tx{tx.tx})
+			bundleGas += tx.gasWanted
+		}
+		if (maxBytes > -1 && selection.totalBytes+bundleBytes > maxBytes) || (maxGas > -1 && selection.totalGas+bundleGas > maxGas) {
+			return selection, ErrMustIncludeBundle{bundleId, height, fmt.Sprintf("doesn't fit: %d bytes and %d gas left, bundle needs %d bytes and %d gas", maxBytes-selection.totalBytes, maxGas-selection.totalGas, bundleBytes, bundleGas)}
+		}
+
+		selected[key] = struct{}{}
+		selection.totalBytes += bundleBytes
+		selection.totalGas += bundleGas
+		selection.bundles = append(selection.bundles, selectedBundle{key: key, bundle: bundle, txs: txs, bytes: bundleBytes})
+		selection.decide(bundle, true, ReapReasonMustInclude)
+	}
+	return selection, nil
+}
+
+// ReapAndSeal reaps the bundles for height like ReapMaxBytesMaxGas, and seals
+// height in the same lock acquisition: orders for it are rejected from then
+// on, whatever SidecarConfig.CurrentHeightPolicy, so no bundle added after
+// the call can change what was reaped for it. height must be the auction
+// height. The seal lapses once the height is committed.
+//
+// Safe for concurrent use by multiple goroutines, it holds the lock while it
+// runs.
+func (sc *CListPriorityTxSidecar) ReapAndSeal(height, maxBytes, maxGas int64) ([]*MempoolTx, error) {
+	sc.updateMtx.Lock()
+	if height != sc.heightForFiringAuction {
+		sc.updateMtx.Unlock()
+		return nil, ErrWrongHeight{
+			int(height),
+			int(sc.heightForFiringAuction),
+		}
+	}
+
+	auction := types.EventDataSidecarAuction{}
+	reapedBundles := make([]types.EventDataSidecarBundle, 0)
+	defer sc.publishReapEvents(&auction, &reapedBundles)
+	defer sc.updateMtx.Unlock()
+
+	memTxs, _, _ := sc.reapLocked(make([]*MempoolTx, 0, sc.txs.Len()), sc.selectBundles(maxBytes, maxGas), nil, &auction, &reapedBundles)
+	atomic.StoreInt64(&sc.sealedHeight, height)
+	fmt.Println(fmt.Sprintf("[mev-tendermint]: ReapAndSeal(): sealed height %d after reaping %d txs", height, len(memTxs)))
+	return memTxs, nil
+}
+
+// ReapMaxTxsWithDeadline reaps the same txs as ReapMaxTxs, but if some
+// bundles for the auction height are still missing orders, it first waits for
+// them to complete, for at most the configured ReapGracePeriod or until ctx is
+// done. It never waits if ReapGracePeriod is 0.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapMaxTxsWithDeadline(ctx context.Context) []*MempoolTx {
+	if grace := sc.ReapGracePeriod(); grace > 0 {
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+	WAIT:
+		for {
+			// grab the channel before checking, so we can't miss an order
+			// added in between
+			orderAdded := sc.orderAddedChan()
+			if !sc.hasIncompleteBundles(sc.HeightForFiringAuction()) {
+				break
+			}
+			select {
+			case <-orderAdded:
+			case <-timer.C:
+				fmt.Println("[mev-tendermint]: ReapMaxTxsWithDeadline(): grace period elapsed with incomplete bundles, reaping anyway")
+				break WAIT
+			case <-ctx.Done():
+				break WAIT
+			}
+		}
+	}
+	return sc.ReapMaxTxs()
+}
+
+// hasIncompleteBundles returns true if any bundle for height is still missing
+// orders, or waiting on admission.
+func (sc *CListPriorityTxSidecar) hasIncompleteBundles(height int64) bool {
+	incomplete := false
+	sc.bundles.Range(func(key, value interface{}) bool {
+		bundle := value.(*Bundle)
+		if key.(Key).height != height {
+			return true
+		}
+		if !bundle.isComplete() || atomic.LoadInt32(&bundle.pendingAdmission) == 1 {
+			incomplete = true
+			return false
+		}
+		return true
+	})
+	return incomplete
+}
+
+// IncompleteBundles returns the bundles that are still missing orders, along
+// with the orders they're missing, sorted by height and bundle id.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) IncompleteBundles() []IncompleteBundleInfo {
+	sc.updateMtx.RLock()
+	defer sc.updateMtx.RUnlock()
+
+	incomplete := make([]IncompleteBundleInfo, 0)
+	sc.bundles.Range(func(_, value interface{}) bool {
+		bundle := value.(*Bundle)
+		if bundle.isComplete() {
+			return true
+		}
+		info := IncompleteBundleInfo{
+			DesiredHeight: bundle.desiredHeight,
+			BundleId:      bundle.bundleId,
+			BundleSize:    bundle.enforcedSize,
+			MissingOrders: make([]int64, 0),
+		}
+		for bundleOrderIter := int64(0); bundleOrderIter < bundle.enforcedSize; bundleOrderIter++ {
+			if _, ok := bundle.orderedTxsMap.Load(bundleOrderIter); !ok {
+				info.MissingOrders = append(info.MissingOrders, bundleOrderIter)
+			}
+		}
+		incomplete = append(incomplete, info)
+		return true
+	})
+	sort.Slice(incomplete, func(i, j int) bool {
+		if incomplete[i].DesiredHeight != incomplete[j].DesiredHeight {
+			return incomplete[i].DesiredHeight < incomplete[j].DesiredHeight
+		}
+		return incomplete[i].BundleId < incomplete[j].BundleId
+	})
+	return incomplete
+}
+
+func (sc *CListPriorityTxSidecar) orderAddedChan() <-chan struct{} {
+	sc.orderAddedMtx.Lock()
+	defer sc.orderAddedMtx.Unlock()
+	return sc.orderAdded
+}
+
+// notifyOrderAdded wakes up everyone waiting on orderAddedChan
+func (sc *CListPriorityTxSidecar) notifyOrderAdded() {
+	sc.orderAddedMtx.Lock()
+	defer sc.orderAddedMtx.Unlock()
+	close(sc.orderAdded)
+	sc.orderAdded = make(chan struct{})
+}
+
+// PeerBundleStats returns a copy of the stats of every peer that sent the
+// sidecar a tx, over the node's lifetime.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) PeerBundleStats() map[uint16]PeerStats {
+	sc.peerStatsMtx.Lock()
+	defer sc.peerStatsMtx.Unlock()
+	stats := make(map[uint16]PeerStats, len(sc.peerStats))
+	for peerID, peerStats := range sc.peerStats {
+		stats[peerID] = *peerStats
+	}
+	return stats
+}
+
+func (sc *CListPriorityTxSidecar) updatePeerStats(peerID uint16, update func(*PeerStats)) {
+	sc.peerStatsMtx.Lock()
+	defer sc.peerStatsMtx.Unlock()
+	stats, ok := sc.peerStats[peerID]
+	if !ok {
+		stats = &PeerStats{}
+		sc.peerStats[peerID] = stats
+	}
+	update(stats)
+}
+
+// LastReapWinners returns, in ascending order, the ids of the peers whose
+// bundles were included in the last reap. A bundle is attributed to the peer
+// that sent its first order.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) LastReapWinners() []uint16 {
+	sc.lastReapMtx.Lock()
+	defer sc.lastReapMtx.Unlock()
+	return append([]uint16(nil), sc.lastReapWinners...)
+}
+
+func (sc *CListPriorityTxSidecar) setLastReapWinners(winners map[uint16]struct{}) {
+	sorted := make([]uint16, 0, len(winners))
+	for peerID := range winners {
+		sorted = append(sorted, peerID)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	sc.lastReapMtx.Lock()
+	defer sc.lastReapMtx.Unlock()
+	sc.lastReapWinners = sorted
+}
+
+// Reasons a reap did or didn't include a bundle, see ReapDecision.
+const (
+	ReapReasonIncluded         = "included"
+	ReapReasonMustInclude      = "must_include"         // included first, see ReapWithMustInclude
+	ReapReasonPartial          = "partial"              // see SidecarConfig.AllowPartialBundles
+	ReapReasonIncomplete       = "incomplete"           // still missing orders
+	ReapReasonPendingAdmission = "pending_admission"    // see SetBundleAdmissionHook
+	ReapReasonExcludedPeer     = "excluded_peer"        // see SetReapExcludedPeers
+	ReapReasonOverBudget       = "over_budget"          // doesn't fit in what's left of the byte or gas budget
+	ReapReasonMaxBundles       = "max_bundles_per_reap" // see SidecarConfig.MaxBundlesPerReap
+	ReapReasonMinOrders        = "min_orders_to_reap"   // too few orders for a partial reap, see TxInfo.MinOrdersToReap
+)
+
+// ReapDecision is why a reap did or didn't include a bundle.
+type ReapDecision struct {
+	BundleMeta
+	Included bool
+	Reason   string // one of the ReapReason constants
+}
+
+// LastReapAudit returns, in the order the last reap considered them, every
+// bundle for the auction height it went over, and why it did or didn't
+// include each. Only reaps that fire the auction are audited: SimulateReap
+// and ReapPage aren't.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) LastReapAudit() []ReapDecision {
+	sc.lastReapMtx.Lock()
+	defer sc.lastReapMtx.Unlock()
+	return append([]ReapDecision(nil), sc.lastReapAudit...)
+}
+
+func (sc *CListPriorityTxSidecar) setLastReapAudit(decisions []ReapDecision) {
+	sc.lastReapMtx.Lock()
+	defer sc.lastReapMtx.Unlock()
+	sc.lastReapAudit = decisions
+}
+
+// FiredHeights returns, oldest first, up to limit (all kept, if limit <= 0)
+// of the last heights auctions were fired for, by reaping the sidecar.
+// Reaping the same height again isn't recorded, so a height that's fired
+// but never moves on shows up last. Up to 100 heights are kept.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) FiredHeights(limit int) []int64 {
+	sc.firedHeightsMtx.Lock()
+	defer sc.firedHeightsMtx.Unlock()
+
+	n := sc.numFiredHeights
+	if n > firedHeightsHistory {
+		n = firedHeightsHistory
+	}
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	heights := make([]int64, n)
+	for i := range heights {
+		heights[i] = sc.firedHeights[(sc.numFiredHeights-n+i)%firedHeightsHistory]
+	}
+	return heights
+}
+
+func (sc *CListPriorityTxSidecar) recordFiredHeight(height int64) {
+	sc.firedHeightsMtx.Lock()
+	defer sc.firedHeightsMtx.Unlock()
+
+	if sc.numFiredHeights > 0 && sc.firedHeights[(sc.numFiredHeights-1)%firedHeightsHistory] == height {
+		return
+	}
+	sc.firedHeights[sc.numFiredHeights%firedHeightsHistory] = height
+	sc.numFiredHeights++
+	sc.firedAtHeight = height
+	sc.firedAt = sc.now()
+}
+
+// observeAuctionToCommit records the time since the auction for height fired,
+// if it did, now that height is committed.
+func (sc *CListPriorityTxSidecar) observeAuctionToCommit(height int64) {
+	sc.firedHeightsMtx.Lock()
+	defer sc.firedHeightsMtx.Unlock()
+
+	if sc.firedAt.IsZero() || sc.firedAtHeight != height {
+		return
+	}
+	sc.metrics.SidecarAuctionToCommitSeconds.Observe(sc.now().Sub(sc.firedAt).Seconds())
+	// a height committed again, e.g. on a reorg, isn't observed twice
+	sc.firedAt = time.Time{}
+}
+
+// ReapCursor is a position in the sidecar's reap order, see ReapPage. The zero
+// value starts from the first bundle of the current auction height.
+type ReapCursor struct {
+	height      int64
+	bundleId    int64
+	sender      uint16
+	bundleOrder int64
+	numBundles  int // bundles returned so far, see SidecarConfig.MaxBundlesPerReap
+	done        bool
+}
+
+// Done returns true once ReapPage has returned every tx.
+func (c ReapCursor) Done() bool {
+	return c.done
+}
+
+// ReapPage returns up to limit txs (all remaining, if limit <= 0) from the
+// txs ReapMaxTxs would reap, starting at cursor, along with the cursor for
+// the next page. Unlike the other reaps, it doesn't mark the bundles as
+// reaped or close the auction height to new orders, so it can be used to
+// inspect the auction.
+// The cursor references bundles by id and order, so it stays valid as bundles
+// are added or removed: bundles that are gone are skipped, and bundles added
+// behind the cursor won't be returned. Once the auction height moves on, the
+// cursor is Done. Bundles prioritized for starving, see
+// SidecarStarvedBundlePrioritize, are returned in their usual place. Only
+// complete bundles are returned: the prefixes of incomplete bundles a reap
+// may take with SidecarConfig.AllowPartialBundles depend on its budget, so
+// they're never paged through.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapPage(cursor ReapCursor, limit int) ([]*MempoolTx, ReapCursor) {
 	sc.updateMtx.RLock()
 	defer sc.updateMtx.RUnlock()
 
+	if cursor.height == 0 {
+		cursor.height = sc.heightForFiringAuction
+	}
+	if cursor.done || cursor.height != sc.heightForFiringAuction {
+		return nil, ReapCursor{height: cursor.height, done: true}
+	}
+
+	memTxs := make([]*MempoolTx, 0)
+	for _, key := range sc.bundleKeys(cursor.height) {
+		if key.bundleId < cursor.bundleId || (key.bundleId == cursor.bundleId && key.sender < cursor.sender) {
+			continue
+		}
+		firstOrder := int64(0)
+		if key.bundleId == cursor.bundleId && key.sender == cursor.sender {
+			firstOrder = cursor.bundleOrder
+		}
+
+		bundleVal, ok := sc.bundles.Load(key)
+		if !ok {
+			continue
+		}
+		bundle := bundleVal.(*Bundle)
+		if !bundle.isComplete() || atomic.LoadInt32(&bundle.pendingAdmission) == 1 || sc.reapExcludePeers[bundle.senderID] {
+			continue
+		}
+		if firstOrder == 0 {
+			if maxBundles := sc.config.MaxBundlesPerReap; maxBundles > 0 && cursor.numBundles >= maxBundles {
+				break
+			}
+			if limit > 0 && len(memTxs) == limit {
+				return memTxs, ReapCursor{height: cursor.height, bundleId: key.bundleId, sender: key.sender, numBundles: cursor.numBundles}
+			}
+			// counted once started, so a page ending within it doesn't count it twice
+			cursor.numBundles++
+		}
+		for bundleOrder := firstOrder; bundleOrder < bundle.enforcedSize; bundleOrder++ {
+			if limit > 0 && len(memTxs) == limit {
+				return memTxs, ReapCursor{height: cursor.height, bundleId: key.bundleId, sender: key.sender, bundleOrder: bundleOrder, numBundles: cursor.numBundles}
+			}
+			if scTx, ok := bundle.orderedTxsMap.Load(bundleOrder); ok {
+				memTxs = append(memTxs, scTx.(*SidecarTx).toMempoolTx())
+			}
+		}
+	}
+	return memTxs, ReapCursor{height: cursor.height, done: true}
+}
+
+// reapMaxBytesMaxGasInto is the core of every reap, returning the reaped txs
+// along with their total bytes and gas. If visit isn't nil, it's called with
+// each bundle as it's reaped, along with the bundle's txs.
+func (sc *CListPriorityTxSidecar) reapMaxBytesMaxGasInto(
+	buf []*MempoolTx,
+	maxBytes, maxGas int64,
+	visit func(bundle *Bundle, memTxs []*MempoolTx),
+) ([]*MempoolTx, int64, int64) {
+	// events are published once the lock is released, so slow subscribers
+	// don't hold up the sidecar
+	auction := types.EventDataSidecarAuction{}
+	reapedBundles := make([]types.EventDataSidecarBundle, 0)
+	defer sc.publishReapEvents(&auction, &reapedBundles)
+
+	sc.updateMtx.RLock()
+	defer sc.updateMtx.RUnlock()
+
+	return sc.reapLocked(buf, sc.selectBundles(maxBytes, maxGas), visit, &auction, &reapedBundles)
+}
+
+// reapLocked does the work of reapMaxBytesMaxGasInto, reaping the given
+// selection and recording the auction and the reaped bundles for their events
+// to be published by the caller, once it releases the lock.
+//
+// The lock must be held by the caller during execution, at least for reading.
+func (sc *CListPriorityTxSidecar) reapLocked(
+	buf []*MempoolTx,
+	selection reapSelection,
+	visit func(bundle *Bundle, memTxs []*MempoolTx),
+	auction *types.EventDataSidecarAuction,
+	reapedBundles *[]types.EventDataSidecarBundle,
+) ([]*MempoolTx, int64, int64) {
 	fmt.Println(fmt.Sprintf("REAPING SIDECAR via ReapMaxTxs(): sidecar size at this time is %d", sc.Size()))
+	auction.Height = sc.heightForFiringAuction
+
+	// from now on, orders for this height may be rejected, see auctionHeightClosed
+	atomic.StoreInt64(&sc.reapedHeight, sc.heightForFiringAuction)
+	sc.recordFiredHeight(sc.heightForFiringAuction)
+
+	memTxs := buf[:0]
+	winners := make(map[uint16]struct{})
+	defer sc.setLastReapWinners(winners)
+	defer sc.setLastReapAudit(selection.decisions)
+
+	// concurrent reaps may select the same incomplete bundles, only one of
+	// them evicts each, see removeBundle
+	if sc.config.StrictMode {
+		for _, selected := range selection.incomplete {
+			sc.strictEvict(selected.key, selected.bundle, selected.bundle.senderID, "missing orders when reaped")
+		}
+	}
+
+	sc.countBudgetSkips(selection)
 
-	memTxs := make([]*MempoolTx, 0, sc.txs.Len())
+	for _, selected := range selection.bundles {
+		bundle := selected.bundle
+		bundleStart := len(memTxs)
+		for _, scTx := range selected.txs {
+			// loading as sidecar tx, but casting to MempoolTx to return
+			memTxs = append(memTxs, scTx.toMempoolTx())
+		}
+		auction.NumTxs += len(selected.txs)
+		if selected.partial {
+			fmt.Println(fmt.Sprintf("ReapMaxTxs() PARTIAL BUNDLE...: reaped %d of %d orders of bundleId %d at height %d", len(selected.txs), bundle.enforcedSize, bundle.bundleId, sc.heightForFiringAuction))
+		} else {
+			if atomic.CompareAndSwapInt32(&bundle.reaped, 0, 1) {
+				sc.updatePeerStats(bundle.senderID, func(stats *PeerStats) { stats.ReapedBundles++ })
+				atomic.AddInt64(&sc.heightReapedBundles, 1)
+				atomic.AddInt64(&sc.heightReapedBytes, selected.bytes)
+			}
+			winners[bundle.senderID] = struct{}{}
+			*reapedBundles = append(*reapedBundles, bundleEventData(bundle))
+			auction.NumBundles++
+		}
+		if visit != nil {
+			visit(bundle, memTxs[bundleStart:])
+		}
+	}
+
+	return memTxs, selection.totalBytes, selection.totalGas
+}
 
+// countBudgetSkips starts the count of reaps in a row over for the complete
+// bundles in selection, and adds one for the bundles it skipped for not
+// fitting in the budget, applying SidecarConfig.StarvedBundlePolicy to the
+// ones reaching StarvedBundleThreshold.
+//
+// The lock must be held by the caller during execution, at least for reading.
+func (sc *CListPriorityTxSidecar) countBudgetSkips(selection reapSelection) {
+	for _, selected := range selection.bundles {
+		if !selected.partial {
+			atomic.StoreInt32(&selected.bundle.budgetSkips, 0)
+		}
+	}
+
+	threshold := sc.config.StarvedBundleThreshold
+	for _, skipped := range selection.skipped {
+		bundle := skipped.bundle
+		if skips := atomic.AddInt32(&bundle.budgetSkips, 1); threshold == 0 || int(skips) != threshold {
+			continue
+		}
+		sc.metrics.StarvedSidecarBundles.Add(1)
+		if sc.config.StarvedBundlePolicy == cfg.SidecarStarvedBundlePrioritize {
+			fmt.Println(fmt.Sprintf("ReapMaxTxs() STARVED BUNDLE...: bundleId %d at height %d didn't fit in %d reaps in a row, prioritizing it", bundle.bundleId, bundle.desiredHeight, threshold))
+			atomic.StoreInt32(&bundle.prioritized, 1)
+			continue
+		}
+		// the reap holds only the read lock, so an add may have evicted the
+		// bundle since it was selected, see removeBundle
+		if sc.removeBundle(skipped.key, bundle) {
+			fmt.Println(fmt.Sprintf("ReapMaxTxs() STARVED BUNDLE...: bundleId %d at height %d didn't fit in %d reaps in a row, evicted it", bundle.bundleId, bundle.desiredHeight, threshold))
+		}
+	}
+}
+
+// reapOrder returns keys, as returned by bundleKeys, in the order the reaps
+// consider them: bundles prioritized for starving first, see
+// SidecarStarvedBundlePrioritize, then the others.
+//
+// The lock must be held by the caller during execution, at least for reading.
+func (sc *CListPriorityTxSidecar) reapOrder(keys []Key) []Key {
+	ordered := make([]Key, 0, len(keys))
+	rest := make([]Key, 0, len(keys))
+	for _, key := range keys {
+		if bundle, ok := sc.bundles.Load(key); ok && atomic.LoadInt32(&bundle.(*Bundle).prioritized) == 1 {
+			ordered = append(ordered, key)
+		} else {
+			rest = append(rest, key)
+		}
+	}
+	return append(ordered, rest...)
+}
+
+// selectedBundle is a bundle picked by selectBundles, with the txs taken from
+// it, in bundle order.
+type selectedBundle struct {
+	key    Key
+	bundle *Bundle
+	txs    []*SidecarTx
+	bytes  int64 // of txs, as proto encoded in a block
+	// only a prefix of the bundle's orders is taken, see
+	// SidecarConfig.AllowPartialBundles
+	partial bool
+}
+
+// reapSelection is what a reap takes from the sidecar, see selectBundles.
+type reapSelection struct {
+	bundles              []selectedBundle
+	totalBytes, totalGas int64
+	// incomplete bundles passed over before the reap stopped, which a reap
+	// evicts in strict mode
+	incomplete []selectedBundle
+	// complete bundles passed over for not fitting in the budget, which may
+	// starve, see SidecarConfig.StarvedBundleThreshold
+	skipped []selectedBundle
+	// every bundle considered, in the order it was, see LastReapAudit
+	decisions []ReapDecision
+}
+
+// decide records why the reap did or didn't take bundle. A bundle already
+// decided on, e.g. an incomplete bundle later taken partially, is decided on
+// again in place.
+func (selection *reapSelection) decide(bundle *Bundle, included bool, reason string) {
+	decision := ReapDecision{BundleMeta: bundleMeta(bundle), Included: included, Reason: reason}
+	for i := range selection.decisions {
+		if selection.decisions[i].BundleMeta == decision.BundleMeta {
+			selection.decisions[i] = decision
+			return
+		}
+	}
+	selection.decisions = append(selection.decisions, decision)
+}
+
+// selectBundles picks, in reap order, the bundles for the auction height a
+// reap with the given budget takes, without changing anything, so reaps and
+// SimulateReap agree.
+//
+// The lock must be held by the caller during execution, at least for reading.
+func (sc *CListPriorityTxSidecar) selectBundles(maxBytes, maxGas int64) reapSelection {
+	return sc.selectBundlesAfter(reapSelection{}, maxBytes, maxGas)
+}
+
+// selectBundlesAfter is selectBundles, but starting from the bundles already
+// in selection, which are kept ahead of the others and count against the
+// budget and MaxBundlesPerReap, see ReapWithMustInclude.
+//
+// The lock must be held by the caller during execution, at least for reading.
+func (sc *CListPriorityTxSidecar) selectBundlesAfter(selection reapSelection, maxBytes, maxGas int64) reapSelection {
 	if (sc.txs.Len() == 0) || (sc.NumBundles() == 0) {
-		return memTxs
+		return selection
+	}
+
+	maxBytes = sc.reapBytesBudget(maxBytes)
+
+	// like the mempool, a zero budget reaps nothing, even bundles that want no gas
+	if maxBytes == 0 || maxGas == 0 {
+		return selection
 	}
 
-	// iterate over all bundleIds up to the max we've seen
+	selected := make(map[Key]struct{}, len(selection.bundles))
+	for _, already := range selection.bundles {
+		selected[already.key] = struct{}{}
+	}
+
+	// iterate over all bundles for the auction height, by bundleId
 	// CONTRACT: this assumes that bundles don't care about previous bundles, so still want to execute if any missing between
-	for bundleIdIter := 0; bundleIdIter <= int(sc.maxBundleId); bundleIdIter++ {
-		bundleIdIter := int64(bundleIdIter)
+	numBundles := len(selection.bundles)
+	stopped := false
+	for _, key := range sc.reapOrder(sc.bundleKeys(sc.heightForFiringAuction)) {
+		bundleIdIter := key.bundleId
+		if _, ok := selected[key]; ok {
+			continue
+		}
+
+		// the bundles left are only gone over for the audit
+		if maxBundles := sc.config.MaxBundlesPerReap; maxBundles > 0 && numBundles >= maxBundles {
+			if !stopped {
+				fmt.Println(fmt.Sprintf("ReapMaxTxs() STOPPING...: already reaped the maximum of %d bundles at height %d", maxBundles, sc.heightForFiringAuction))
+				stopped = true
+			}
+			if bundle, ok := sc.bundles.Load(key); ok {
+				selection.decide(bundle.(*Bundle), false, ReapReasonMaxBundles)
+			}
+			continue
+		}
 
-		if bundle, ok := sc.bundles.Load(Key{sc.heightForFiringAuction, bundleIdIter}); ok {
+		if bundle, ok := sc.bundles.Load(key); ok {
 			bundle := bundle.(*Bundle)
 			bundleOrderedTxsMap := bundle.orderedTxsMap
 
 			// check to see if bundle is full, if not, just skip now
-			if bundle.currSize != bundle.enforcedSize {
-				fmt.Println(fmt.Sprintf("ReapMaxTxs() SKIPPING BUNDLE...: size mismatch for bundleId %d at height %d: currSize %d, enforcedSize %d: SKIPPING...", bundleIdIter, sc.heightForFiringAuction, bundle.currSize, bundle.enforcedSize))
+			if !bundle.isComplete() {
+				fmt.Println(fmt.Sprintf("ReapMaxTxs() SKIPPING BUNDLE...: size mismatch for bundleId %d at height %d: currSize %d, enforcedSize %d: SKIPPING...", bundleIdIter, sc.heightForFiringAuction, atomic.LoadInt64(&bundle.currSize), bundle.enforcedSize))
+				selection.incomplete = append(selection.incomplete, selectedBundle{key: key, bundle: bundle})
+				selection.decide(bundle, false, ReapReasonIncomplete)
+				continue
+			}
+			if atomic.LoadInt32(&bundle.pendingAdmission) == 1 {
+				fmt.Println(fmt.Sprintf("ReapMaxTxs() SKIPPING BUNDLE...: bundleId %d at height %d is pending admission", bundleIdIter, sc.heightForFiringAuction))
+				selection.decide(bundle, false, ReapReasonPendingAdmission)
+				continue
+			}
+			if sc.reapExcludePeers[bundle.senderID] {
+				fmt.Println(fmt.Sprintf("ReapMaxTxs() SKIPPING BUNDLE...: bundleId %d at height %d is from excluded peer %d", bundleIdIter, sc.heightForFiringAuction, bundle.senderID))
+				selection.decide(bundle, false, ReapReasonExcludedPeer)
 				continue
 			}
 
-			// if full, iterate over bundle in order and add txs to temporary store, then add all if we have enough (i.e. matches enforcedBundleSize)
-			innerTxs := make([]*MempoolTx, 0, bundle.enforcedSize)
+			// if full, iterate over bundle in order and collect its txs, then drop them if we don't have enough (i.e. doesn't match enforcedBundleSize)
+			txs := make([]*SidecarTx, 0, bundle.enforcedSize)
+			var bundleBytes, bundleGas int64
 			for bundleOrderIter := 0; bundleOrderIter < int(bundle.enforcedSize); bundleOrderIter++ {
 				bundleOrderIter := int64(bundleOrderIter)
 
 				if scTx, ok := bundleOrderedTxsMap.Load(bundleOrderIter); ok {
-					// loading as sidecar tx, but casting to MempoolTx to return
 					scTx := scTx.(*SidecarTx)
-					memTx := &MempoolTx{
-						// CONTRACT: since the only height this could have been added into is desiredHeight = mem.height + 1, then this tx must have been validated against mem.height
-						height:    scTx.desiredHeight - 1,
-						gasWanted: scTx.gasWanted,
-						tx:        scTx.tx,
-						senders:   scTx.senders,
-					}
-					innerTxs = append(innerTxs, memTx)
+					txs = append(txs, scTx)
+					bundleBytes += types.ComputeProtoSizeForTxs([]types.Tx{scTx.tx})
+					bundleGas += scTx.gasWanted
 				} else {
 					// can't find tx at this bundleOrder for this bundleId
 					fmt.Println(fmt.Sprintf("ReapMaxTxs() skip: don't have memTx for bundleOrder %d bundleId %d at height %d", bundleOrderIter, bundleIdIter, sc.heightForFiringAuction))
@@ -473,19 +2723,159 @@ func (sc *CListPriorityTxSidecar) ReapMaxTxs() []*MempoolTx {
 			}
 
 			// check to see if we have the right number of transactions for the bundle, comparing to the enforced size
-			if bundle.enforcedSize == int64(len(innerTxs)) {
-				// check to see if we've reaped the right number of txs expected for the bundle
-				memTxs = append(memTxs, innerTxs...)
-			} else {
-				fmt.Println(fmt.Sprintf("ReapMaxTxs() SKIPPING BUNDLE...: size mismatch for bundleId %d at height %d: reaped %d, bundleSize %d, enforcedBundleSize %d: SKIPPING...", bundleIdIter, sc.heightForFiringAuction, len(innerTxs), bundle.currSize, bundle.enforcedSize))
+			if reaped := len(txs); bundle.enforcedSize != int64(reaped) {
+				fmt.Println(fmt.Sprintf("ReapMaxTxs() SKIPPING BUNDLE...: size mismatch for bundleId %d at height %d: reaped %d, bundleSize %d, enforcedBundleSize %d: SKIPPING...", bundleIdIter, sc.heightForFiringAuction, reaped, atomic.LoadInt64(&bundle.currSize), bundle.enforcedSize))
+				selection.decide(bundle, false, ReapReasonIncomplete)
+				continue
 			}
+
+			// check the whole bundle fits in what's left of the byte and gas budget
+			if (maxBytes > -1 && selection.totalBytes+bundleBytes > maxBytes) || (maxGas > -1 && selection.totalGas+bundleGas > maxGas) {
+				fmt.Println(fmt.Sprintf("ReapMaxTxs() SKIPPING BUNDLE...: bundleId %d at height %d doesn't fit: %d bytes and %d gas left, bundle needs %d bytes and %d gas", bundleIdIter, sc.heightForFiringAuction, maxBytes-selection.totalBytes, maxGas-selection.totalGas, bundleBytes, bundleGas))
+				selection.skipped = append(selection.skipped, selectedBundle{key: key, bundle: bundle})
+				selection.decide(bundle, false, ReapReasonOverBudget)
+				continue
+			}
+			selection.totalBytes += bundleBytes
+			selection.totalGas += bundleGas
+			selection.bundles = append(selection.bundles, selectedBundle{key: key, bundle: bundle, txs: txs, bytes: bundleBytes})
+			selection.decide(bundle, true, ReapReasonIncluded)
+			numBundles++
 		} else {
 			// can't find a bundle for this bundleId, panic! (incomplete gossipping)
 			fmt.Println(fmt.Sprintf("ReapMaxTxs() SKIPPING BUNDLE...: don't have bundle entry for bundleId %d at height %d", bundleIdIter, sc.heightForFiringAuction))
 		}
 	}
 
-	return memTxs
+	// in strict mode, incomplete bundles are evicted rather than cut
+	if sc.config.AllowPartialBundles && !sc.config.StrictMode {
+		sc.selectPartialBundles(&selection, maxBytes, maxGas)
+	}
+	return selection
+}
+
+// reapBytesBudget returns the byte budget of a reap asked for maxBytes, which
+// the per-height cap bounds whatever budget the caller passes.
+func (sc *CListPriorityTxSidecar) reapBytesBudget(maxBytes int64) int64 {
+	if capBytes := sc.config.MaxReapBytesPerHeight; capBytes > 0 && (maxBytes < 0 || maxBytes > capBytes) {
+		return capBytes
+	}
+	return maxBytes
+}
+
+// selectPartialBundles adds to selection, as a second pass of selectBundles,
+// the contiguous prefixes of the incomplete bundles for the auction height
+// that fit in what's left of the byte and gas budget, see
+// SidecarConfig.AllowPartialBundles.
+//
+// The lock must be held by the caller during execution, at least for reading.
+func (sc *CListPriorityTxSidecar) selectPartialBundles(selection *reapSelection, maxBytes, maxGas int64) {
+	for _, key := range sc.bundleKeys(sc.heightForFiringAuction) {
+		value, ok := sc.bundles.Load(key)
+		if !ok {
+			continue
+		}
+		bundle := value.(*Bundle)
+		if bundle.isComplete() || atomic.LoadInt32(&bundle.pendingAdmission) == 1 || sc.reapExcludePeers[bundle.senderID] {
+			continue
+		}
+
+		txs := make([]*SidecarTx, 0)
+		var bundleBytes, bundleGas int64
+		for bundleOrderIter := int64(0); bundleOrderIter < bundle.enforcedSize; bundleOrderIter++ {
+			scTx, ok := bundle.orderedTxsMap.Load(bundleOrderIter)
+			if !ok {
+				break
+			}
+			tx := scTx.(*SidecarTx)
+			txBytes := types.ComputeProtoSizeForTxs([]types.Tx{tx.tx})
+			if (maxBytes > -1 && selection.totalBytes+bundleBytes+txBytes > maxBytes) ||
+				(maxGas > -1 && selection.totalGas+bundleGas+tx.gasWanted > maxGas) {
+				break
+			}
+			txs = append(txs, tx)
+			bundleBytes += txBytes
+			bundleGas += tx.gasWanted
+		}
+		if len(txs) == 0 {
+			continue
+		}
+		if int64(len(txs)) < bundle.minOrdersToReap {
+			fmt.Println(fmt.Sprintf("ReapMaxTxs() SKIPPING BUNDLE...: only %d of the %d orders bundleId %d at height %d needs for a partial reap", len(txs), bundle.minOrdersToReap, bundle.bundleId, sc.heightForFiringAuction))
+			selection.decide(bundle, false, ReapReasonMinOrders)
+			continue
+		}
+		// partial bundles count towards the cap like complete ones
+		if maxBundles := sc.config.MaxBundlesPerReap; maxBundles > 0 && len(selection.bundles) >= maxBundles {
+			selection.decide(bundle, false, ReapReasonMaxBundles)
+			continue
+		}
+		selection.totalBytes += bundleBytes
+		selection.totalGas += bundleGas
+		selection.bundles = append(selection.bundles, selectedBundle{key: key, bundle: bundle, txs: txs, bytes: bundleBytes, partial: true})
+		selection.decide(bundle, true, ReapReasonPartial)
+	}
+}
+
+// ReapPlan is what a reap would take from the sidecar, see SimulateReap.
+type ReapPlan struct {
+	Bundles    []PlannedBundle // in reap order
+	NumTxs     int
+	TotalBytes int64
+	TotalGas   int64
+}
+
+// PlannedBundle is a bundle a ReapPlan takes.
+type PlannedBundle struct {
+	BundleMeta
+	NumTxs int
+	// only a prefix of the bundle's orders is taken, see
+	// SidecarConfig.AllowPartialBundles
+	Partial bool
+}
+
+// SimulateReap returns what ReapMaxBytesMaxGas would reap with the given
+// budget, without reaping: no auction fires, and the sidecar is left as is,
+// e.g. incomplete bundles aren't evicted in strict mode. It only holds the
+// read lock while it plans, so a later reap matches the plan as long as the
+// sidecar doesn't change in between.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) SimulateReap(maxBytes, maxGas int64) ReapPlan {
+	sc.updateMtx.RLock()
+	defer sc.updateMtx.RUnlock()
+
+	selection := sc.selectBundles(maxBytes, maxGas)
+	plan := ReapPlan{
+		Bundles:    make([]PlannedBundle, 0, len(selection.bundles)),
+		TotalBytes: selection.totalBytes,
+		TotalGas:   selection.totalGas,
+	}
+	for _, selected := range selection.bundles {
+		plan.Bundles = append(plan.Bundles, PlannedBundle{
+			BundleMeta: bundleMeta(selected.bundle),
+			NumTxs:     len(selected.txs),
+			Partial:    selected.partial,
+		})
+		plan.NumTxs += len(selected.txs)
+	}
+	return plan
+}
+
+// publishReapEvents publishes an event for every reaped bundle, then for the
+// auction they were reaped for.
+func (sc *CListPriorityTxSidecar) publishReapEvents(
+	auction *types.EventDataSidecarAuction,
+	reapedBundles *[]types.EventDataSidecarBundle,
+) {
+	for _, data := range *reapedBundles {
+		if err := sc.eventBus.PublishEventSidecarBundleReaped(data); err != nil {
+			fmt.Println(fmt.Sprintf("[mev-tendermint]: ReapMaxTxs(): failed publishing reaped event for bundle with id %d: %v", data.BundleId, err))
+		}
+	}
+	if err := sc.eventBus.PublishEventSidecarAuctionFired(*auction); err != nil {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: ReapMaxTxs(): failed publishing auction event for height %d: %v", auction.Height, err))
+	}
 }
 
 // Safe for concurrent use by multiple goroutines.
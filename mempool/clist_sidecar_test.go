@@ -0,0 +1,2677 @@
+package mempool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/abci/example/kvstore"
+	abci "github.com/tendermint/tendermint/abci/types"
+	cfg "github.com/tendermint/tendermint/config"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+	"github.com/tendermint/tendermint/mempool/mempooltest"
+	"github.com/tendermint/tendermint/proxy"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestSidecarReapMaxTxsInto(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0},
+		{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1},
+	}, UnknownPeerID)
+	// an incomplete bundle must not leave any of its txs behind in the buffer
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 2}, 0)
+
+	expected := sidecar.ReapMaxTxs()
+	require.Len(t, expected, 5)
+
+	buf := make([]*MempoolTx, 0, 10)
+	got := sidecar.ReapMaxTxsInto(buf)
+	require.Len(t, got, len(expected))
+	for i := range expected {
+		assert.Equal(t, expected[i].tx, got[i].tx, "tx #%d differs", i)
+	}
+	assert.Equal(t, &buf[:1][0], &got[:1][0], "expected the buffer's backing array to be reused")
+
+	// reaping again into the returned slice yields the same txs
+	again := sidecar.ReapMaxTxsInto(got)
+	require.Len(t, again, len(expected))
+	for i := range expected {
+		assert.Equal(t, expected[i].tx, again[i].tx, "tx #%d differs", i)
+	}
+
+	// a nil buffer behaves like an empty one
+	assert.Len(t, sidecar.ReapMaxTxsInto(nil), len(expected))
+}
+
+func TestSidecarUpdateWithPartiallyCommittedBundle(t *testing.T) {
+	tests := []struct {
+		policy        string
+		expectedTxs   int
+		expectedBytes int64
+	}{
+		{cfg.SidecarBundlePolicyEvict, 0, 0},
+		{cfg.SidecarBundlePolicyRequeue, 2, 40},
+	}
+	for _, tt := range tests {
+		config := cfg.TestSidecarConfig()
+		config.UncommittedBundlePolicy = tt.policy
+		sidecar := NewCListSidecar(config, 0)
+
+		bInfo := testBundleInfo{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0}
+		txs := createSidecarBundleAndTxs(t, sidecar, bInfo)
+		// an unreaped bundle is always evicted
+		addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1}, 0)
+		require.Len(t, sidecar.ReapMaxTxs(), 3)
+
+		// the block only had room for the first tx of the bundle
+		err := sidecar.Update(1, txs[:1], abciResponses(1, abci.CodeTypeOK))
+		require.NoError(t, err)
+
+		assert.Equal(t, tt.expectedTxs, sidecar.Size(), "policy %s", tt.policy)
+		assert.Equal(t, tt.expectedBytes, sidecar.TxsBytes(), "policy %s", tt.policy)
+		reaped := sidecar.ReapMaxTxs()
+		require.Len(t, reaped, tt.expectedTxs, "policy %s", tt.policy)
+		for i, memTx := range reaped {
+			assert.Equal(t, txs[i+1], memTx.tx, "policy %s", tt.policy)
+		}
+
+		// a requeued bundle is only requeued if reaped again
+		err = sidecar.Update(2, types.Txs{}, abciResponses(0, abci.CodeTypeOK))
+		require.NoError(t, err)
+		assert.Equal(t, tt.expectedTxs, sidecar.Size(), "policy %s", tt.policy)
+		err = sidecar.Update(3, types.Txs{}, abciResponses(0, abci.CodeTypeOK))
+		require.NoError(t, err)
+		assert.Equal(t, 0, sidecar.Size(), "policy %s", tt.policy)
+	}
+}
+
+func TestSidecarMaxBufferedOrders(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.MaxBufferedOrders = 4
+	sidecar := NewCListSidecar(config, 0)
+
+	// three incomplete bundles, buffering 4 orders, with bundle 0 progressing last
+	bundles := make([]testBundleInfo, 3)
+	for i := range bundles {
+		bundles[i] = testBundleInfo{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: int64(i)}
+	}
+	addTxToSidecar(t, sidecar, bundles[0], 0)
+	addTxToSidecar(t, sidecar, bundles[1], 0)
+	addTxToSidecar(t, sidecar, bundles[2], 0)
+	addTxToSidecar(t, sidecar, bundles[0], 1)
+	require.Equal(t, 3, sidecar.NumBundles())
+	require.Equal(t, 4, sidecar.Size())
+
+	// completed bundles don't count towards the limit
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 3})
+	require.Equal(t, 4, sidecar.NumBundles())
+
+	// going over the limit evicts bundle 1, which least recently progressed
+	addTxToSidecar(t, sidecar, bundles[2], 1)
+	assert.Equal(t, 3, sidecar.NumBundles())
+	assert.Equal(t, 0, sidecar.GetCurrBundleSize(1))
+	assert.Equal(t, 2, sidecar.GetCurrBundleSize(0))
+	assert.Equal(t, 2, sidecar.GetCurrBundleSize(2))
+	assert.Equal(t, 5, sidecar.Size())
+	assert.EqualValues(t, 5*20, sidecar.TxsBytes())
+
+	// then bundle 0
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 4}, 0)
+	assert.Equal(t, 0, sidecar.GetCurrBundleSize(0))
+	assert.Equal(t, 2, sidecar.GetCurrBundleSize(2))
+	assert.Equal(t, 1, sidecar.GetCurrBundleSize(4))
+	assert.Equal(t, 1, sidecar.GetCurrBundleSize(3))
+}
+
+// TestSidecarReapOrdering pins the order ReapMaxTxs returns txs in, which
+// integrators rely on: by ascending bundle id, then ascending bundle order,
+// no matter the order the txs arrived in.
+func TestSidecarReapOrdering(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+
+	bundleSizes := []int64{3, 1, 4, 2}
+	bundles := make([]types.Txs, len(bundleSizes))
+	for i, size := range bundleSizes {
+		bundles[i] = mempooltest.GenerateTestBundle(397, mempooltest.TestBundleInfo{
+			BundleSize: size, DesiredHeight: 1, BundleId: int64(i),
+		})
+	}
+
+	// interleave the bundles, adding the highest bundle id and order first
+	arrivals := []struct {
+		bundleId, bundleOrder int64
+	}{
+		{3, 1}, {2, 3}, {0, 2}, {2, 0}, {1, 0}, {0, 0},
+		{2, 2}, {3, 0}, {0, 1}, {2, 1},
+	}
+	for _, a := range arrivals {
+		err := sidecar.AddTx(bundles[a.bundleId][a.bundleOrder], TxInfo{
+			SenderID:      UnknownPeerID,
+			DesiredHeight: 1,
+			BundleId:      a.bundleId,
+			BundleOrder:   a.bundleOrder,
+			BundleSize:    bundleSizes[a.bundleId],
+		})
+		require.NoError(t, err)
+	}
+
+	expected := make(types.Txs, 0, len(arrivals))
+	for _, bundle := range bundles {
+		expected = append(expected, bundle...)
+	}
+	for i := 0; i < 3; i++ {
+		reaped := sidecar.ReapMaxTxs()
+		got := make(types.Txs, len(reaped))
+		for j, memTx := range reaped {
+			got[j] = memTx.tx
+		}
+		require.Equal(t, expected, got, "reap #%d", i)
+	}
+}
+
+func TestSidecarReapTxs(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0},
+		{BundleSize: 5, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1},
+		{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 2},
+	}, UnknownPeerID)
+
+	// each tx takes 22 bytes once proto encoded
+	tests := []struct {
+		maxBytes, maxGas int64
+		expectedNumTxs   int
+	}{
+		{-1, -1, 9},
+		{22 * 9, -1, 9},
+		// bundle 1 doesn't fit, but bundle 2 after it does
+		{22 * 5, -1, 4},
+		{22 * 2, -1, 1},
+	}
+	for tcIndex, tt := range tests {
+		memTxs, _, _ := sidecar.ReapMaxBytesMaxGas(tt.maxBytes, tt.maxGas)
+		txs := sidecar.ReapTxs(tt.maxBytes, tt.maxGas)
+		require.Len(t, memTxs, tt.expectedNumTxs, "tc #%d", tcIndex)
+		require.Len(t, txs, len(memTxs), "tc #%d", tcIndex)
+		for i, memTx := range memTxs {
+			assert.Equal(t, memTx.tx, txs[i], "tx #%d, tc #%d", i, tcIndex)
+		}
+	}
+}
+
+func TestSidecarReapMaxBytesMaxGasBudgets(t *testing.T) {
+	// the app wants 1 gas per tx
+	appConn, err := proxy.NewLocalClientCreator(&rejectingApp{}).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop() // nolint:errcheck
+
+	// mirrors TestReapMaxBytesMaxGasMempool, with single tx bundles:
+	// each tx takes 22 bytes once proto encoded
+	tests := []struct {
+		numBundlesToCreate int
+		maxBytes           int64
+		maxGas             int64
+		expectedNumTxs     int
+	}{
+		{1, 100, 100, 1},
+		{20, -1, -1, 20},
+		{20, -1, 0, 0},
+		{20, -1, 10, 10},
+		{20, -1, 30, 20},
+		{20, 0, -1, 0},
+		{20, 0, 10, 0},
+		{20, 10, 10, 0},
+		{20, 24, 10, 1},
+		{20, 240, 5, 5},
+		{20, 240, -1, 10},
+		{20, 240, 10, 10},
+		{20, 240, 15, 10},
+		{20, 20000, -1, 20},
+		{20, 20000, 5, 5},
+		{20, 20000, 30, 20},
+	}
+	for tcIndex, tt := range tests {
+		sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0, WithSidecarProxyAppConn(appConn))
+		addNumBundlesToSidecar(t, sidecar, tt.numBundlesToCreate, 1, UnknownPeerID)
+
+		memTxs, _, _ := sidecar.ReapMaxBytesMaxGas(tt.maxBytes, tt.maxGas)
+		assert.Len(t, memTxs, tt.expectedNumTxs, "tc #%d", tcIndex)
+	}
+
+	// bundles that want no gas still aren't reaped with a zero budget
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	addNumBundlesToSidecar(t, sidecar, 2, 1, UnknownPeerID)
+	memTxs, _, _ := sidecar.ReapMaxBytesMaxGas(-1, 0)
+	assert.Empty(t, memTxs)
+	memTxs, _, _ = sidecar.ReapMaxBytesMaxGas(0, -1)
+	assert.Empty(t, memTxs)
+	memTxs, _, _ = sidecar.ReapMaxBytesMaxGas(-1, -1)
+	assert.Len(t, memTxs, 2)
+}
+
+func TestSidecarUpdateNonMonotonicHeight(t *testing.T) {
+	for _, allowReorg := range []bool{false, true} {
+		config := cfg.TestSidecarConfig()
+		config.AllowReorgUpdates = allowReorg
+		sidecar := NewCListSidecar(config, 0)
+
+		require.NoError(t, sidecar.Update(5, types.Txs{}, abciResponses(0, abci.CodeTypeOK)))
+		// updating to the same height again is fine
+		require.NoError(t, sidecar.Update(5, types.Txs{}, abciResponses(0, abci.CodeTypeOK)))
+
+		err := sidecar.Update(3, types.Txs{}, abciResponses(0, abci.CodeTypeOK))
+		if allowReorg {
+			require.NoError(t, err)
+			assert.EqualValues(t, 4, sidecar.HeightForFiringAuction())
+		} else {
+			require.Error(t, err)
+			assert.IsType(t, ErrNonMonotonicUpdate{}, err)
+			assert.EqualValues(t, 6, sidecar.HeightForFiringAuction())
+		}
+	}
+}
+
+func TestSidecarAddBundleReceipt(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+
+	txs := types.Txs{[]byte{0x01}, []byte{0x02, 0x03}, []byte{0x04, 0x05, 0x06, 0x07}}
+	receipt, err := sidecar.AddBundle(txs, TxInfo{SenderID: UnknownPeerID, DesiredHeight: 1, BundleId: 4})
+	require.NoError(t, err)
+	assert.Equal(t, BundleReceipt{DesiredHeight: 1, BundleId: 4, NumTxs: 3, TotalBytes: 7, TotalGas: 0}, receipt)
+	assert.Equal(t, 3, sidecar.GetCurrBundleSize(4))
+	assert.Len(t, sidecar.ReapMaxTxs(), 3)
+
+	// the receipt only covers the txs accepted before an error
+	txs = types.Txs{[]byte{0x08, 0x09}, []byte{0x01}}
+	receipt, err = sidecar.AddBundle(txs, TxInfo{SenderID: UnknownPeerID, DesiredHeight: 1, BundleId: 5})
+	assert.Equal(t, ErrTxInCache, err)
+	assert.Equal(t, BundleReceipt{DesiredHeight: 1, BundleId: 5, NumTxs: 1, TotalBytes: 2, TotalGas: 0}, receipt)
+}
+
+// randomTxs returns count random 20 byte txs
+func randomTxs(count int) types.Txs {
+	txs := make(types.Txs, count)
+	for i := range txs {
+		txs[i] = tmrand.Bytes(20)
+	}
+	return txs
+}
+
+// slowAppConnMempool accepts every tx in CheckTx after a delay, tracking the
+// number of txs it checks at once
+type slowAppConnMempool struct {
+	proxy.AppConnMempool
+	delay time.Duration
+
+	inFlight, maxInFlight int32
+}
+
+func (conn *slowAppConnMempool) CheckTxSync(req abci.RequestCheckTx) (*abci.ResponseCheckTx, error) {
+	inFlight := atomic.AddInt32(&conn.inFlight, 1)
+	defer atomic.AddInt32(&conn.inFlight, -1)
+	for {
+		maxInFlight := atomic.LoadInt32(&conn.maxInFlight)
+		if inFlight <= maxInFlight || atomic.CompareAndSwapInt32(&conn.maxInFlight, maxInFlight, inFlight) {
+			break
+		}
+	}
+	time.Sleep(conn.delay)
+	return &abci.ResponseCheckTx{Code: abci.CodeTypeOK, GasWanted: 1}, nil
+}
+
+func TestSidecarAddBundleCheckTxWorkers(t *testing.T) {
+	app := &rejectingApp{reject: types.Tx("bad")}
+	appConn, err := proxy.NewLocalClientCreator(app).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop() // nolint:errcheck
+
+	config := cfg.TestSidecarConfig()
+	config.CheckTxWorkers = 2
+	sidecar := NewCListSidecar(config, 0, WithSidecarProxyAppConn(appConn))
+
+	// none of the txs are added if the app rejects one
+	txs := types.Txs{types.Tx("a"), types.Tx("b"), types.Tx("bad"), types.Tx("c"), types.Tx("d")}
+	receipt, err := sidecar.AddBundle(txs, TxInfo{SenderID: UnknownPeerID, DesiredHeight: 1, BundleId: 0})
+	assert.Equal(t, ErrTxRejectedForBundle{0, 1, 1}, err)
+	assert.Zero(t, receipt.NumTxs)
+	assert.Zero(t, sidecar.Size())
+	assert.Zero(t, sidecar.NumBundles())
+
+	// the good txs weren't cached, and can make it in another bundle
+	txs = types.Txs{types.Tx("a"), types.Tx("b"), types.Tx("c"), types.Tx("d")}
+	receipt, err = sidecar.AddBundle(txs, TxInfo{SenderID: UnknownPeerID, DesiredHeight: 1, BundleId: 0})
+	require.NoError(t, err)
+	assert.Equal(t, 4, receipt.NumTxs)
+	assert.EqualValues(t, 4, receipt.TotalGas)
+	assert.Len(t, sidecar.ReapMaxTxs(), 4)
+
+	// txs are checked concurrently, up to the number of workers
+	slowConn := &slowAppConnMempool{delay: 5 * time.Millisecond}
+	config.CheckTxWorkers = 3
+	sidecar = NewCListSidecar(config, 0, WithSidecarProxyAppConn(slowConn))
+	receipt, err = sidecar.AddBundle(randomTxs(9), TxInfo{SenderID: UnknownPeerID, DesiredHeight: 1, BundleId: 0})
+	require.NoError(t, err)
+	assert.Equal(t, 9, receipt.NumTxs)
+	assert.LessOrEqual(t, atomic.LoadInt32(&slowConn.maxInFlight), int32(3))
+	assert.Len(t, sidecar.ReapMaxTxs(), 9)
+}
+
+func TestSidecarMaxConcurrentBundleChecks(t *testing.T) {
+	// with one worker per bundle, the txs in flight are the bundles in flight
+	config := cfg.TestSidecarConfig()
+	config.CheckTxWorkers = 1
+	config.MaxConcurrentBundleChecks = 2
+	slowConn := &slowAppConnMempool{delay: 5 * time.Millisecond}
+	sidecar := NewCListSidecar(config, 0, WithSidecarProxyAppConn(slowConn))
+
+	// bundles over the limit are queued
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(bundleID int64) {
+			defer wg.Done()
+			_, err := sidecar.AddBundle(randomTxs(3), TxInfo{SenderID: UnknownPeerID, DesiredHeight: 1, BundleId: bundleID})
+			assert.NoError(t, err)
+		}(int64(i))
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, atomic.LoadInt32(&slowConn.maxInFlight), int32(2))
+	assert.Equal(t, 8, sidecar.NumBundles())
+
+	// or rejected
+	config.MaxConcurrentBundleChecks = 1
+	config.BundleChecksOverflowPolicy = cfg.SidecarBundleChecksReject
+	slowConn = &slowAppConnMempool{delay: 50 * time.Millisecond}
+	sidecar = NewCListSidecar(config, 0, WithSidecarProxyAppConn(slowConn))
+	done := make(chan error)
+	go func() {
+		_, err := sidecar.AddBundle(randomTxs(1), TxInfo{SenderID: UnknownPeerID, DesiredHeight: 1, BundleId: 0})
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&slowConn.inFlight) == 1 }, time.Second, time.Millisecond)
+	txs := randomTxs(1)
+	_, err := sidecar.AddBundle(txs, TxInfo{SenderID: UnknownPeerID, DesiredHeight: 1, BundleId: 1})
+	assert.Equal(t, ErrBundleChecksBusy{1, 1, 1}, err)
+	require.NoError(t, <-done)
+
+	// and can be resubmitted once the slot frees up
+	_, err = sidecar.AddBundle(txs, TxInfo{SenderID: UnknownPeerID, DesiredHeight: 1, BundleId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, sidecar.NumBundles())
+}
+
+func TestSidecarReapMaxTxsWithDeadline(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.ReapGracePeriod = 10 * time.Second
+	sidecar := NewCListSidecar(config, 0)
+
+	// complete bundles are reaped right away
+	bInfo := testBundleInfo{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0}
+	createSidecarBundleAndTxs(t, sidecar, bInfo)
+	start := time.Now()
+	assert.Len(t, sidecar.ReapMaxTxsWithDeadline(context.Background()), 1)
+	assert.Less(t, int64(time.Since(start)), int64(config.ReapGracePeriod))
+
+	// the last order of a bundle arriving within the grace period is included
+	bInfo = testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1}
+	addTxToSidecar(t, sidecar, bInfo, 0)
+	reaped := make(chan []*MempoolTx)
+	go func() {
+		reaped <- sidecar.ReapMaxTxsWithDeadline(context.Background())
+	}()
+	select {
+	case <-reaped:
+		t.Fatal("reaped before the bundle was complete")
+	case <-time.After(50 * time.Millisecond):
+	}
+	lastTx := addTxToSidecar(t, sidecar, bInfo, 1)
+	select {
+	case memTxs := <-reaped:
+		require.Len(t, memTxs, 3)
+		assert.Equal(t, lastTx, memTxs[2].tx)
+	case <-time.After(config.ReapGracePeriod / 2):
+		t.Fatal("timed out waiting for reap")
+	}
+
+	// a done context stops the wait early, reaping what's complete
+	bInfo = testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 2}
+	addTxToSidecar(t, sidecar, bInfo, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Len(t, sidecar.ReapMaxTxsWithDeadline(ctx), 3)
+}
+
+func TestMempoolTxSource(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	mempool, sidecar, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	checkTxs(t, mempool, 3, UnknownPeerID, sidecar, false)
+	addNumBundlesToSidecar(t, sidecar, 2, 2, UnknownPeerID)
+
+	for e := mempool.TxsFront(); e != nil; e = e.Next() {
+		assert.Equal(t, SourceMempool, e.Value.(*MempoolTx).Source())
+	}
+	scMemTxs := sidecar.ReapMaxTxs()
+	require.Len(t, scMemTxs, 4)
+	for _, memTx := range scMemTxs {
+		assert.Equal(t, SourceSidecar, memTx.Source())
+	}
+}
+
+func TestSidecarConfigGetters(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.MaxBufferedOrders = 42
+	config.ReapGracePeriod = 250 * time.Millisecond
+	config.UncommittedBundlePolicy = cfg.SidecarBundlePolicyRequeue
+	sidecar := NewCListSidecar(config, 0)
+
+	assert.Equal(t, 42, sidecar.MaxBufferedOrders())
+	assert.Equal(t, 250*time.Millisecond, sidecar.ReapGracePeriod())
+	assert.Equal(t, *config, sidecar.Config())
+
+	// the snapshot can't be used to change the sidecar's limits
+	snapshot := sidecar.Config()
+	snapshot.MaxBufferedOrders = 1
+	assert.Equal(t, 42, sidecar.MaxBufferedOrders())
+}
+
+func TestSidecarUpdateConfig(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.MaxSidecarTxs = 10
+	sidecar := NewCListSidecar(config, 0)
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 3, PeerId: 1, DesiredHeight: 1, BundleId: 0})
+
+	// invalid and fixed settings are rejected, leaving the config as it was
+	updated := *config
+	updated.MaxBufferedOrders = 11
+	assert.Error(t, sidecar.UpdateConfig(updated))
+	updated = *config
+	updated.NamespaceBundlesBySender = true
+	assert.EqualError(t, sidecar.UpdateConfig(updated), "namespace_bundles_by_sender can't be changed at runtime")
+	updated = *config
+	updated.BundleOrderBase = 1
+	err := sidecar.UpdateConfig(updated)
+	assert.EqualError(t, err, "bundle_order_base can't be changed at runtime")
+	assert.Equal(t, ErrSidecarSettingFixed{"bundle_order_base"}, err)
+	assert.Equal(t, SidecarCodeSidecarSettingFixed, SidecarErrorCode(err))
+	assert.Equal(t, *config, sidecar.Config())
+
+	// a lowered limit rejects new txs, but keeps the bundle already held
+	updated = *config
+	updated.MaxSidecarTxs = 2
+	require.NoError(t, sidecar.UpdateConfig(updated))
+	assert.Equal(t, updated, sidecar.Config())
+	txInfo := TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 1, BundleSize: 1}
+	assert.Equal(t, ErrSidecarIsFull{3, 2}, sidecar.AddTx(randomTxs(1)[0], txInfo))
+	assert.Equal(t, 3, sidecar.Size())
+	assert.Len(t, sidecar.ReapMaxTxs(), 3)
+
+	// unless asked to, tightened limits don't evict incomplete bundles
+	sidecar = NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	incomplete := testBundleInfo{BundleSize: 3, PeerId: 1, DesiredHeight: 1, BundleId: 0}
+	addTxToSidecar(t, sidecar, incomplete, 0)
+	addTxToSidecar(t, sidecar, incomplete, 1)
+	updated = sidecar.Config()
+	updated.MaxBufferedOrders = 1
+	require.NoError(t, sidecar.UpdateConfig(updated))
+	assert.Equal(t, 2, sidecar.Size())
+	updated.EvictOverLimitsOnConfigUpdate = true
+	require.NoError(t, sidecar.UpdateConfig(updated))
+	assert.Zero(t, sidecar.Size())
+}
+
+// rejectingApp rejects a single tx in CheckTx
+type rejectingApp struct {
+	abci.BaseApplication
+	reject types.Tx
+}
+
+func (app *rejectingApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	if bytes.Equal(req.Tx, app.reject) {
+		return abci.ResponseCheckTx{Code: 1}
+	}
+	return abci.ResponseCheckTx{Code: abci.CodeTypeOK, GasWanted: 1}
+}
+
+// blockingApp blocks in CheckTx until release is closed
+type blockingApp struct {
+	abci.BaseApplication
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (app *blockingApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	select {
+	case app.entered <- struct{}{}:
+	default:
+	}
+	<-app.release
+	return abci.ResponseCheckTx{Code: abci.CodeTypeOK}
+}
+
+func TestSidecarDedicatedABCIClient(t *testing.T) {
+	mempool, _, cleanup := newMempoolWithApp(proxy.NewLocalClientCreator(kvstore.NewApplication()))
+	defer cleanup()
+
+	app := &blockingApp{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	client, err := proxy.NewLocalClientCreator(app).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, client.Start())
+	defer client.Stop() // nolint:errcheck
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0, WithSidecarABCIClient(client))
+
+	added := make(chan error, 1)
+	go func() {
+		_, err := sidecar.AddBundle(randomTxs(2), TxInfo{DesiredHeight: 1, BundleId: 0})
+		added <- err
+	}()
+	select {
+	case <-app.entered:
+	case <-time.After(time.Second):
+		t.Fatal("sidecar never checked the bundle")
+	}
+
+	// the bundle check is stuck in the app, but the mempool has its own
+	// connection
+	checked := make(chan error, 1)
+	go func() { checked <- mempool.CheckTx(types.Tx("key=value"), nil, TxInfo{}) }()
+	select {
+	case err := <-checked:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("mempool CheckTx blocked behind the bundle check")
+	}
+	assert.Equal(t, 1, mempool.Size())
+
+	close(app.release)
+	require.NoError(t, <-added)
+	assert.Len(t, sidecar.ReapMaxTxs(), 2)
+}
+
+func TestSidecarCheckTxRejectsBundle(t *testing.T) {
+	app := &rejectingApp{reject: types.Tx("bad")}
+	appConn, err := proxy.NewLocalClientCreator(app).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop() // nolint:errcheck
+
+	metrics := PrometheusMetrics("sidecar_checktx_test")
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0,
+		WithSidecarProxyAppConn(appConn), WithSidecarMetrics(metrics))
+
+	// a good bundle is added, with the gas the app asks for
+	goodTxs := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, DesiredHeight: 1, BundleId: 0})
+	memTxs := sidecar.ReapMaxTxs()
+	require.Len(t, memTxs, 2)
+	assert.EqualValues(t, 1, memTxs[0].gasWanted)
+
+	// one bad tx drops the whole bundle, including the txs already added
+	txInfo := TxInfo{DesiredHeight: 1, BundleId: 1, BundleSize: 3}
+	require.NoError(t, sidecar.AddTx(types.Tx("good"), txInfo))
+	assert.Equal(t, 3, sidecar.Size())
+	txInfo.BundleOrder = 1
+	err = sidecar.AddTx(app.reject, txInfo)
+	assert.Equal(t, ErrTxRejectedForBundle{1, 1, 1}, err)
+	assert.Equal(t, 2, sidecar.Size())
+	assert.Equal(t, 1, sidecar.NumBundles())
+	assert.EqualValues(t, 1, rejectedSidecarBundles(t, "sidecar_checktx_test", ""))
+	assert.EqualValues(t, len(goodTxs[0])+len(goodTxs[1]), sidecar.TxsBytes())
+
+	// and its txs can be resubmitted
+	txInfo.BundleOrder = 0
+	assert.NoError(t, sidecar.AddTx(types.Tx("good"), txInfo))
+
+	// a bad tx spoofing another peer's bundle doesn't drop it
+	err = sidecar.AddTx(app.reject, TxInfo{SenderID: 2, DesiredHeight: 1, BundleId: 1, BundleOrder: 1, BundleSize: 3})
+	assert.Equal(t, ErrBundleSenderMismatch{1, 1, 2, 0}, err)
+	assert.Equal(t, 1, sidecar.GetCurrBundleSize(1))
+	assert.Equal(t, 3, sidecar.Size())
+}
+
+func TestSidecarABCIRejectedBundlesPerPeer(t *testing.T) {
+	app := &rejectingApp{reject: types.Tx("bad")}
+	appConn, err := proxy.NewLocalClientCreator(app).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop() // nolint:errcheck
+
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0, WithSidecarProxyAppConn(appConn))
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 0})
+
+	// peer 2 started the bundle, so a rejected tx for it from peer 3 is
+	// refused without dropping it
+	txInfo := TxInfo{SenderID: 2, DesiredHeight: 1, BundleId: 1, BundleSize: 2}
+	require.NoError(t, sidecar.AddTx(types.Tx("good"), txInfo))
+	txInfo.SenderID, txInfo.BundleOrder = 3, 1
+	assert.Equal(t, ErrBundleSenderMismatch{1, 1, 3, 2}, sidecar.AddTx(app.reject, txInfo))
+	assert.Equal(t, 1, sidecar.GetCurrBundleSize(1))
+
+	// peer 3 gets a rejected tx counted against it for a bundle of its own
+	txInfo.BundleId = 3
+	assert.Equal(t, ErrTxRejectedForBundle{3, 1, 1}, sidecar.AddTx(app.reject, txInfo))
+
+	// and a whole bundle from peer 3
+	_, err = sidecar.AddBundle(types.Txs{types.Tx("fine"), app.reject}, TxInfo{SenderID: 3, DesiredHeight: 1, BundleId: 2})
+	assert.Equal(t, ErrTxRejectedForBundle{2, 1, 1}, err)
+
+	stats := sidecar.PeerBundleStats()
+	assert.Zero(t, stats[1].ABCIRejectedBundles)
+	assert.Zero(t, stats[2].ABCIRejectedBundles)
+	assert.EqualValues(t, 2, stats[3].ABCIRejectedBundles)
+}
+
+func TestSidecarMetricsLabel(t *testing.T) {
+	app := &rejectingApp{reject: types.Tx("bad")}
+	appConn, err := proxy.NewLocalClientCreator(app).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop() // nolint:errcheck
+
+	// two sidecars sharing the same metrics
+	metrics := PrometheusMetrics("sidecar_label_test")
+	newSidecar := func(label string) *CListPriorityTxSidecar {
+		config := cfg.TestSidecarConfig()
+		config.MetricsLabel = label
+		return NewCListSidecar(config, 0, WithSidecarProxyAppConn(appConn), WithSidecarMetrics(metrics))
+	}
+	slotA, slotB := newSidecar("slot-a"), newSidecar("slot-b")
+
+	for bundleId := int64(0); bundleId < 2; bundleId++ {
+		err := slotA.AddTx(app.reject, TxInfo{DesiredHeight: 1, BundleId: bundleId, BundleSize: 1})
+		require.Error(t, err)
+	}
+	require.Error(t, slotB.AddTx(app.reject, TxInfo{DesiredHeight: 1, BundleId: 5, BundleSize: 1}))
+
+	assert.EqualValues(t, 2, rejectedSidecarBundles(t, "sidecar_label_test", "slot-a"))
+	assert.EqualValues(t, 1, rejectedSidecarBundles(t, "sidecar_label_test", "slot-b"))
+}
+
+// rejectedSidecarBundles returns the value of the rejected_sidecar_bundles
+// counter registered under namespace for the sidecar labeled sidecarLabel
+func rejectedSidecarBundles(t *testing.T, namespace, sidecarLabel string) float64 {
+	return sidecarCounter(t, namespace, "rejected_sidecar_bundles", sidecarLabel)
+}
+
+// sidecarCounter returns the value of the sidecar counter called name
+// registered under namespace, for the sidecar labeled sidecarLabel
+func sidecarCounter(t *testing.T, namespace, name, sidecarLabel string) float64 {
+	families, err := stdprometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != namespace+"_"+MetricsSubsystem+"_"+name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == SidecarMetricsLabel && label.GetValue() == sidecarLabel {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	t.Fatalf("no %s metric for sidecar %q", name, sidecarLabel)
+	return 0
+}
+
+func TestSidecarReapPage(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0},
+		{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1},
+		{BundleSize: 4, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 3},
+	}, UnknownPeerID)
+	// incomplete, so never reaped
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 2}, 0)
+
+	expected := sidecar.ReapTxs(-1, -1)
+	require.Len(t, expected, 9)
+
+	pageAll := func(limit int) types.Txs {
+		var (
+			txs    types.Txs
+			cursor ReapCursor
+			page   []*MempoolTx
+		)
+		for !cursor.Done() {
+			page, cursor = sidecar.ReapPage(cursor, limit)
+			assert.LessOrEqual(t, len(page), limit)
+			for _, memTx := range page {
+				txs = append(txs, memTx.tx)
+			}
+		}
+		return txs
+	}
+	for _, limit := range []int{1, 2, 4, 9, 10} {
+		assert.Equal(t, expected, pageAll(limit), "limit %d", limit)
+	}
+
+	// paging doesn't consume bundles
+	assert.Equal(t, expected, sidecar.ReapTxs(-1, -1))
+
+	// bundles removed ahead of the cursor are skipped, and added ones returned
+	page, cursor := sidecar.ReapPage(ReapCursor{}, 4)
+	require.Len(t, page, 4)
+	bundle, ok := sidecar.bundles.Load(Key{height: 1, bundleId: 1})
+	require.True(t, ok)
+	sidecar.removeBundle(Key{height: 1, bundleId: 1}, bundle.(*Bundle))
+	newTxs := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 4})
+
+	rest, cursor := sidecar.ReapPage(cursor, 0)
+	assert.True(t, cursor.Done())
+	restTxs := make(types.Txs, len(rest))
+	for i, memTx := range rest {
+		restTxs[i] = memTx.tx
+	}
+	// the first page stopped inside bundle 1, which is now gone, so the rest
+	// is all of bundle 3, then the new bundle
+	assert.Equal(t, append(expected[5:], newTxs...), restTxs)
+
+	// a cursor for a previous auction height is done
+	_, cursor = sidecar.ReapPage(ReapCursor{height: 5}, 1)
+	assert.True(t, cursor.Done())
+}
+
+func TestSidecarAddTxCopiesTx(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	buf := []byte{0x01, 0x02, 0x03}
+	require.NoError(t, sidecar.AddTx(buf, TxInfo{DesiredHeight: 1, BundleId: 0, BundleSize: 1}))
+
+	// the caller reuses its buffer
+	buf[0] = 0xff
+
+	memTxs := sidecar.ReapMaxTxs()
+	require.Len(t, memTxs, 1)
+	assert.Equal(t, types.Tx{0x01, 0x02, 0x03}, memTxs[0].tx)
+	_, ok := sidecar.txsMap.Load(TxKey(types.Tx{0x01, 0x02, 0x03}))
+	assert.True(t, ok)
+}
+
+func TestSidecarLastReapWinners(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	assert.Empty(t, sidecar.LastReapWinners())
+
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 2, PeerId: 7, DesiredHeight: 1, BundleId: 0},
+		{BundleSize: 1, PeerId: 3, DesiredHeight: 1, BundleId: 1},
+		{BundleSize: 3, PeerId: 7, DesiredHeight: 1, BundleId: 2},
+	}, UnknownPeerID)
+	// peer 9's bundle is incomplete, so it doesn't win
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 9, DesiredHeight: 1, BundleId: 3}, 0)
+
+	sidecar.ReapMaxTxs()
+	assert.Equal(t, []uint16{3, 7}, sidecar.LastReapWinners())
+
+	// only bundle 1 fits
+	memTxs, _, _ := sidecar.ReapMaxBytesMaxGas(22, -1)
+	require.Len(t, memTxs, 1)
+	assert.Equal(t, []uint16{3}, sidecar.LastReapWinners())
+}
+
+func TestSidecarLateOrderGrace(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.LateOrderGrace = time.Minute
+	sidecar := NewCListSidecar(config, 0)
+
+	lateInfo := testBundleInfo{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0}
+	lateTxs := types.Txs{addTxToSidecar(t, sidecar, lateInfo, 0), addTxToSidecar(t, sidecar, lateInfo, 1)}
+	// missing more than its final order, so it's evicted as usual
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1}, 0)
+	assert.Empty(t, sidecar.ReapMaxTxs())
+
+	// the auction for height 1 fires and the block is committed
+	sidecar.Lock()
+	require.NoError(t, sidecar.Update(1, nil, nil))
+	sidecar.Unlock()
+	assert.Equal(t, 2, sidecar.Size())
+	assert.Equal(t, 1, sidecar.NumBundles())
+	assert.Empty(t, sidecar.ReapMaxTxs())
+
+	// the final order arrives late, and the bundle makes the next reap
+	lateTxs = append(lateTxs, addTxToSidecar(t, sidecar, lateInfo, 2))
+	memTxs := sidecar.ReapMaxTxs()
+	require.Len(t, memTxs, 3)
+	for i, memTx := range memTxs {
+		assert.Equal(t, lateTxs[i], memTx.tx)
+		assert.EqualValues(t, 1, memTx.Height())
+	}
+	assert.Equal(t, 3, sidecar.GetCurrBundleSize(0))
+
+	// once the grace elapses, late orders are rejected and the bundle evicted
+	sidecar.config.LateOrderGrace = time.Millisecond
+	lateInfo = testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 2, BundleId: 1}
+	addTxToSidecar(t, sidecar, lateInfo, 0)
+	sidecar.Lock()
+	require.NoError(t, sidecar.Update(2, nil, nil))
+	sidecar.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	err := sidecar.AddTx(types.Tx("late"), TxInfo{DesiredHeight: 2, BundleId: 1, BundleOrder: 1, BundleSize: 2})
+	assert.Equal(t, ErrBundleHeightInPast{1, 2, 2}, err)
+	sidecar.Lock()
+	require.NoError(t, sidecar.Update(3, nil, nil))
+	sidecar.Unlock()
+	assert.Zero(t, sidecar.Size())
+	assert.Zero(t, sidecar.NumBundles())
+}
+
+func TestSidecarCurrentHeightPolicy(t *testing.T) {
+	tests := []struct {
+		policy         string
+		expectedErr    error
+		expectedNumTxs int
+	}{
+		{cfg.SidecarCurrentHeightEligible, nil, 4},
+		{cfg.SidecarCurrentHeightReject, ErrWrongHeight{1, 2}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			config := cfg.TestSidecarConfig()
+			config.CurrentHeightPolicy = tt.policy
+			sidecar := NewCListSidecar(config, 0)
+
+			// a complete bundle, and one missing an order, before the first reap
+			addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0}, 0)
+			addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1}, 0)
+			// paging through the auction doesn't start reaping for it
+			page, _ := sidecar.ReapPage(ReapCursor{}, 0)
+			require.Len(t, page, 1)
+			require.False(t, sidecar.auctionHeightClosed())
+			require.Len(t, sidecar.ReapMaxTxs(), 1)
+
+			// orders for the auction height once reaping for it started
+			err := sidecar.AddTx(types.Tx("final"), TxInfo{DesiredHeight: 1, BundleId: 1, BundleOrder: 1, BundleSize: 2})
+			assert.Equal(t, tt.expectedErr, err)
+			err = sidecar.AddTx(types.Tx("new"), TxInfo{DesiredHeight: 1, BundleId: 2, BundleOrder: 0, BundleSize: 1})
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Len(t, sidecar.ReapMaxTxs(), tt.expectedNumTxs)
+
+			// orders for the next height are accepted either way
+			require.NoError(t, sidecar.AddTx(types.Tx("next"), TxInfo{DesiredHeight: 2, BundleId: 0, BundleOrder: 0, BundleSize: 1}))
+			sidecar.Lock()
+			require.NoError(t, sidecar.Update(1, nil, nil))
+			sidecar.Unlock()
+			assert.Len(t, sidecar.ReapMaxTxs(), 1)
+		})
+	}
+}
+
+func TestSidecarIncompleteBundles(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	assert.Empty(t, sidecar.IncompleteBundles())
+
+	// a complete bundle isn't reported
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0},
+	}, UnknownPeerID)
+
+	incompleteInfo := testBundleInfo{BundleSize: 4, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1}
+	addTxToSidecar(t, sidecar, incompleteInfo, 2)
+	addTxToSidecar(t, sidecar, incompleteInfo, 0)
+
+	assert.Equal(t, []IncompleteBundleInfo{
+		{DesiredHeight: 1, BundleId: 1, BundleSize: 4, MissingOrders: []int64{1, 3}},
+	}, sidecar.IncompleteBundles())
+
+	// once its orders arrive, the bundle isn't reported anymore
+	addTxToSidecar(t, sidecar, incompleteInfo, 3)
+	addTxToSidecar(t, sidecar, incompleteInfo, 1)
+	assert.Empty(t, sidecar.IncompleteBundles())
+}
+
+func TestSidecarBundleMaxHeight(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 4)
+
+	// a bundle valid for heights 5 through 7
+	txs := types.Txs{types.Tx("first"), types.Tx("second")}
+	for i, tx := range txs {
+		require.NoError(t, sidecar.AddTx(tx, TxInfo{DesiredHeight: 5, MaxHeight: 7, BundleId: 0, BundleOrder: int64(i), BundleSize: 2}))
+	}
+
+	for height := int64(5); height <= 7; height++ {
+		require.Equal(t, height, sidecar.HeightForFiringAuction())
+		memTxs := sidecar.ReapMaxTxs()
+		require.Len(t, memTxs, 2, "height %d", height)
+		for i, memTx := range memTxs {
+			assert.Equal(t, txs[i], memTx.tx, "height %d", height)
+		}
+
+		// the bundle isn't committed at this height
+		sidecar.Lock()
+		require.NoError(t, sidecar.Update(height, nil, nil))
+		sidecar.Unlock()
+	}
+
+	// past its max height, the bundle is evicted
+	assert.Empty(t, sidecar.ReapMaxTxs())
+	assert.Zero(t, sidecar.Size())
+	assert.Zero(t, sidecar.NumBundles())
+}
+
+func TestSidecarNamespaceBundlesBySender(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.NamespaceBundlesBySender = true
+	sidecar := NewCListSidecar(config, 0)
+
+	// two peers both using bundle id 0, with bundles of different sizes
+	peerTxs := map[uint16]types.Txs{
+		1: {types.Tx("peer 1, order 0"), types.Tx("peer 1, order 1")},
+		2: {types.Tx("peer 2, order 0"), types.Tx("peer 2, order 1"), types.Tx("peer 2, order 2")},
+	}
+	for _, peerID := range []uint16{2, 1} {
+		txs := peerTxs[peerID]
+		for i, tx := range txs {
+			require.NoError(t, sidecar.AddTx(tx, TxInfo{SenderID: peerID, DesiredHeight: 1, BundleId: 0, BundleOrder: int64(i), BundleSize: int64(len(txs))}))
+		}
+	}
+	assert.Equal(t, 2, sidecar.NumBundles())
+
+	// both bundles are reaped, by sender
+	expected := append(append(types.Txs{}, peerTxs[1]...), peerTxs[2]...)
+	assert.Equal(t, expected, sidecar.ReapTxs(-1, -1))
+
+	// which ReapPage agrees with, cutting through both bundles
+	memTxs, cursor := sidecar.ReapPage(ReapCursor{}, 3)
+	rest, cursor := sidecar.ReapPage(cursor, 0)
+	require.True(t, cursor.Done())
+	got := make(types.Txs, 0, len(expected))
+	for _, memTx := range append(memTxs, rest...) {
+		got = append(got, memTx.tx)
+	}
+	assert.Equal(t, expected, got)
+
+	// without namespacing, the second peer's orders clash with the first's bundle
+	sidecar = NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	for i, tx := range peerTxs[1] {
+		require.NoError(t, sidecar.AddTx(tx, TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 0, BundleOrder: int64(i), BundleSize: 2}))
+	}
+	err := sidecar.AddTx(peerTxs[2][0], TxInfo{SenderID: 2, DesiredHeight: 1, BundleId: 0, BundleOrder: 0, BundleSize: 3})
+	assert.IsType(t, ErrBundleSenderMismatch{}, err)
+	assert.Equal(t, 1, sidecar.NumBundles())
+}
+
+func TestSidecarHeightStatsEvent(t *testing.T) {
+	eventBus := types.NewEventBus()
+	require.NoError(t, eventBus.Start())
+	defer eventBus.Stop() // nolint:errcheck
+
+	sub, err := eventBus.Subscribe(context.Background(), "test", types.EventQuerySidecarHeightStats, 10)
+	require.NoError(t, err)
+
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0, WithSidecarEventBus(eventBus))
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 0},
+		{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 1},
+		{BundleSize: 1, PeerId: 1, DesiredHeight: 2, BundleId: 0},
+	}, 1)
+	// reaping again doesn't count the bundles twice
+	require.Len(t, sidecar.ReapMaxTxs(), 3)
+	require.Len(t, sidecar.ReapMaxTxs(), 3)
+	assert.Equal(t, 1, sidecar.RemoveBundlesForHeight(2))
+
+	update := func(height int64) {
+		sidecar.Lock()
+		defer sidecar.Unlock()
+		require.NoError(t, sidecar.Update(height, nil, nil))
+	}
+	update(1)
+	update(2)
+
+	expected := []types.EventDataSidecarHeightStats{
+		{Height: 1, ReapedBundles: 2, ReapedBytes: 66, EvictedBundles: 1},
+		{Height: 2},
+	}
+	for i, data := range expected {
+		select {
+		case msg := <-sub.Out():
+			assert.Equal(t, data, msg.Data(), "event #%d", i)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event #%d", i)
+		}
+	}
+}
+
+func TestDeriveBundleID(t *testing.T) {
+	txKeys := [][TxKeySize]byte{TxKey(types.Tx("first")), TxKey(types.Tx("second"))}
+
+	id := DeriveBundleID(1, txKeys)
+	assert.GreaterOrEqual(t, id, int64(0))
+	assert.Equal(t, id, DeriveBundleID(1, txKeys))
+
+	// ids change with the sender, and with the txs and their order
+	assert.NotEqual(t, id, DeriveBundleID(2, txKeys))
+	assert.NotEqual(t, id, DeriveBundleID(1, txKeys[:1]))
+	assert.NotEqual(t, id, DeriveBundleID(1, [][TxKeySize]byte{txKeys[1], txKeys[0]}))
+
+	// derived ids are sparse, which the reaps don't mind
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	for i, tx := range []types.Tx{types.Tx("first"), types.Tx("second")} {
+		require.NoError(t, sidecar.AddTx(tx, TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: id, BundleOrder: int64(i), BundleSize: 2}))
+	}
+	assert.Len(t, sidecar.ReapMaxTxs(), 2)
+}
+
+func TestSidecarEvents(t *testing.T) {
+	eventBus := types.NewEventBus()
+	require.NoError(t, eventBus.Start())
+	defer eventBus.Stop() // nolint:errcheck
+
+	sub, err := eventBus.Subscribe(context.Background(), "test", tmquery.MustParse("tm.event CONTAINS 'Sidecar'"), 10)
+	require.NoError(t, err)
+
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0, WithSidecarEventBus(eventBus))
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 0},
+	}, 1)
+	// an incomplete bundle is neither accepted nor reaped
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 1}, 0)
+	require.Len(t, sidecar.ReapMaxTxs(), 2)
+
+	bundle := types.EventDataSidecarBundle{Height: 1, BundleId: 0, BundleSize: 2, SenderID: 1}
+	expected := []types.TMEventData{
+		bundle,
+		bundle,
+		types.EventDataSidecarAuction{Height: 1, NumBundles: 1, NumTxs: 2},
+	}
+	for i, data := range expected {
+		select {
+		case msg := <-sub.Out():
+			assert.Equal(t, data, msg.Data(), "event #%d", i)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event #%d", i)
+		}
+	}
+	select {
+	case msg := <-sub.Out():
+		t.Fatalf("unexpected event %v", msg.Data())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSidecarAddTxAlreadyInBundle(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+
+	// a bundle for the height after the auction's
+	txInfo := TxInfo{SenderID: 1, DesiredHeight: 2, BundleId: 0, BundleOrder: 0, BundleSize: 2}
+	tx := types.Tx("order 0")
+	require.NoError(t, sidecar.AddTx(tx, txInfo))
+	assert.Equal(t, ErrTxInCache, sidecar.AddTx(tx, txInfo))
+
+	// the cache is reset once the block is committed, but the order is still held
+	sidecar.Lock()
+	require.NoError(t, sidecar.Update(1, nil, nil))
+	sidecar.Unlock()
+	err := sidecar.AddTx(tx, txInfo)
+	assert.Equal(t, ErrTxAlreadyInBundle, err)
+	assert.Equal(t, SidecarCodeTxAlreadyInBundle, SidecarErrorCode(err))
+
+	assert.Equal(t, 1, sidecar.Size())
+	assert.Equal(t, 1, sidecar.GetCurrBundleSize(0))
+	// duplicates aren't held against the peer
+	assert.Zero(t, sidecar.PeerBundleStats()[1].RejectedTxs)
+}
+
+func TestSidecarBundleConflict(t *testing.T) {
+	metrics := PrometheusMetrics("sidecar_conflict_test")
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0, WithSidecarMetrics(metrics))
+
+	first := randomTxs(2)
+	for order, tx := range first {
+		txInfo := TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 0, BundleOrder: int64(order), BundleSize: 2}
+		require.NoError(t, sidecar.AddTx(tx, txInfo))
+	}
+
+	// another bundle with the same sender, id and height
+	second := randomTxs(2)
+	err := sidecar.AddTx(second[0], TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 0, BundleOrder: 0, BundleSize: 2})
+	assert.Equal(t, ErrBundleConflict{0, 1, 0}, err)
+	assert.Equal(t, SidecarCodeBundleConflict, SidecarErrorCode(err))
+	// or with another size
+	err = sidecar.AddTx(second[1], TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 0, BundleOrder: 1, BundleSize: 3})
+	assert.Equal(t, ErrTxMalformedForBundle{0, 3, 1, 1}, err)
+
+	assert.EqualValues(t, 2, sidecarCounter(t, "sidecar_conflict_test", "conflicting_sidecar_bundle_txs", ""))
+	assert.EqualValues(t, 2, sidecar.PeerBundleStats()[1].RejectedTxs)
+
+	// the first bundle is untouched
+	memTxs := sidecar.ReapMaxTxs()
+	require.Len(t, memTxs, 2)
+	assert.Equal(t, first[0], memTxs[0].tx)
+	assert.Equal(t, first[1], memTxs[1].tx)
+}
+
+func TestSidecarStrictMode(t *testing.T) {
+	addGappedBundle := func(sidecar *CListPriorityTxSidecar) {
+		// orders 0 and 2 of 3, from peer 1
+		bInfo := testBundleInfo{BundleSize: 3, PeerId: 1, DesiredHeight: 1, BundleId: 0}
+		addTxToSidecar(t, sidecar, bInfo, 0)
+		addTxToSidecar(t, sidecar, bInfo, 2)
+	}
+
+	// by default, the gap is tolerated in case the order still shows up
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	addGappedBundle(sidecar)
+	assert.Empty(t, sidecar.ReapMaxTxs())
+	assert.Equal(t, 2, sidecar.Size())
+
+	config := cfg.TestSidecarConfig()
+	config.StrictMode = true
+	sidecar = NewCListSidecar(config, 0)
+	addGappedBundle(sidecar)
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: 2, DesiredHeight: 1, BundleId: 1})
+	assert.Len(t, sidecar.ReapMaxTxs(), 1)
+	assert.Equal(t, 1, sidecar.Size(), "the bundle with a gap is evicted once its height is reaped")
+	assert.EqualValues(t, 1, sidecar.PeerBundleStats()[1].StrictEvictions)
+	assert.Zero(t, sidecar.PeerBundleStats()[2].StrictEvictions)
+
+	// an order drifting from its bundle's metadata evicts the bundle, and
+	// counts against the peer that sent it
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 2, DesiredHeight: 2, BundleId: 0}, 0)
+	err := sidecar.AddTx(types.Tx("drift"), TxInfo{SenderID: 2, DesiredHeight: 2, BundleId: 0, BundleOrder: 1, BundleSize: 3})
+	assert.Equal(t, ErrTxMalformedForBundle{0, 3, 2, 1}, err)
+	_, ok := sidecar.loadBundle(2, 0)
+	assert.False(t, ok)
+	assert.EqualValues(t, 1, sidecar.PeerBundleStats()[2].StrictEvictions)
+	require.NoError(t, sidecar.CheckInvariants())
+}
+
+func TestSidecarStrictModeConcurrentReaps(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.StrictMode = true
+	sidecar := NewCListSidecar(config, 0)
+	var evicted int32
+	sidecar.SetBundleEvictionHook(func(BundleMeta) { atomic.AddInt32(&evicted, 1) })
+
+	// orders 0 and 2 of 3, from peer 1
+	bInfo := testBundleInfo{BundleSize: 3, PeerId: 1, DesiredHeight: 1, BundleId: 0}
+	addTxToSidecar(t, sidecar, bInfo, 0)
+	addTxToSidecar(t, sidecar, bInfo, 2)
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: 2, DesiredHeight: 1, BundleId: 1})
+
+	// every reap selects the gapped bundle, but it's evicted once
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Len(t, sidecar.ReapMaxTxs(), 1)
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, evicted)
+	assert.EqualValues(t, 1, sidecar.PeerBundleStats()[1].StrictEvictions)
+	assert.Equal(t, 1, sidecar.Size())
+	require.NoError(t, sidecar.CheckInvariants())
+}
+
+func TestSidecarSoftMaxTxsBytes(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	// 20 byte txs: room for 5 of them
+	config.SoftMaxTxsBytes = 100
+	sidecar := NewCListSidecar(config, 0, WithSidecarMetrics(PrometheusMetrics("sidecar_soft_limit_test")))
+
+	// a complete bundle, and two incomplete ones, take 80 bytes
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0},
+	}, UnknownPeerID)
+	stale := testBundleInfo{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1}
+	addTxToSidecar(t, sidecar, stale, 0)
+	fresh := testBundleInfo{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 2}
+	addTxToSidecar(t, sidecar, fresh, 0)
+	require.EqualValues(t, 80, sidecar.TxsBytes())
+	require.Equal(t, 3, sidecar.NumBundles())
+
+	// crossing the limit evicts the stale bundle, bringing usage back down
+	addTxToSidecar(t, sidecar, fresh, 1)
+	addTxToSidecar(t, sidecar, fresh, 2)
+	assert.EqualValues(t, 100, sidecar.TxsBytes())
+	assert.Equal(t, 2, sidecar.NumBundles())
+	assert.Zero(t, sidecar.GetCurrBundleSize(1))
+	assert.EqualValues(t, 1, sidecarCounter(t, "sidecar_soft_limit_test", "soft_limit_evicted_sidecar_bundles", ""))
+
+	// complete bundles are never evicted, even over the limit
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 3},
+	}, UnknownPeerID)
+	assert.EqualValues(t, 120, sidecar.TxsBytes())
+	assert.Len(t, sidecar.ReapMaxTxs(), 6)
+}
+
+func TestSidecarSoftMaxTxsBytesConcurrentEviction(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.SoftMaxTxsBytes = 100
+	sidecar := NewCListSidecar(config, 0, WithSidecarMetrics(PrometheusMetrics("sidecar_soft_limit_race_test")))
+	var evicted int32
+	sidecar.SetBundleEvictionHook(func(BundleMeta) { atomic.AddInt32(&evicted, 1) })
+
+	// a stale incomplete bundle, and complete ones up to the limit
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0}, 0)
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 4, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1},
+	}, UnknownPeerID)
+	require.EqualValues(t, 100, sidecar.TxsBytes())
+
+	// concurrent adds over the limit all race to evict the stale bundle
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(bundleID int64) {
+			defer wg.Done()
+			addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: bundleID}, 0)
+		}(int64(2 + i))
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, evicted)
+	assert.EqualValues(t, 1, sidecarCounter(t, "sidecar_soft_limit_race_test", "soft_limit_evicted_sidecar_bundles", ""))
+	assert.Zero(t, sidecar.GetCurrBundleSize(0))
+	assert.Equal(t, 12, sidecar.Size())
+	assert.EqualValues(t, 12*20, sidecar.TxsBytes())
+	require.NoError(t, sidecar.CheckInvariants())
+}
+
+func TestSidecarOnPeerDisconnect(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	// from peer 1: an incomplete bundle, a complete one, and an incomplete
+	// one with a pinned tx
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 0}, 0)
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 1})
+	require.NoError(t, sidecar.AddTx(types.Tx("pinned"), TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 2, BundleSize: 2, Pinned: true}))
+	// and an incomplete bundle from peer 2
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 2, DesiredHeight: 2, BundleId: 0}, 0)
+	require.Equal(t, 5, sidecar.Size())
+
+	sidecar.OnPeerDisconnect(1)
+	assert.Equal(t, 4, sidecar.Size())
+	assert.Equal(t, 3, sidecar.NumBundles())
+	_, ok := sidecar.loadBundle(1, 0)
+	assert.False(t, ok, "incomplete bundle from the peer is evicted")
+	assert.True(t, sidecar.IsBundleComplete(1, 1))
+	_, ok = sidecar.loadBundle(1, 2)
+	assert.True(t, ok, "pinned bundle is kept")
+	_, ok = sidecar.loadBundle(2, 0)
+	assert.True(t, ok, "other peers' bundles are kept")
+	assert.EqualValues(t, 1, sidecar.PeerBundleStats()[1].EvictedBundles)
+	require.NoError(t, sidecar.CheckInvariants())
+
+	// pinned bundles go too under the evict policy
+	config := cfg.TestSidecarConfig()
+	config.PinnedBundlePolicy = cfg.SidecarPinnedBundleEvict
+	sidecar = NewCListSidecar(config, 0)
+	require.NoError(t, sidecar.AddTx(types.Tx("pinned"), TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 0, BundleSize: 2, Pinned: true}))
+	sidecar.OnPeerDisconnect(1)
+	assert.Zero(t, sidecar.Size())
+}
+
+func TestSidecarMaxSidecarTxs(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.MaxSidecarTxs = 3
+	// far from any byte limit
+	config.SoftMaxTxsBytes = 1 << 20
+	sidecar := NewCListSidecar(config, 0)
+
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0})
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1}, 0)
+	require.Equal(t, 3, sidecar.Size())
+
+	// even the order completing a bundle is rejected
+	err := sidecar.AddTx(types.Tx("order 1"), TxInfo{DesiredHeight: 1, BundleId: 1, BundleOrder: 1, BundleSize: 2})
+	assert.Equal(t, ErrSidecarIsFull{3, 3}, err)
+	assert.Equal(t, SidecarCodeSidecarIsFull, SidecarErrorCode(err))
+	assert.Equal(t, 3, sidecar.Size())
+	assert.Less(t, sidecar.TxsBytes(), config.SoftMaxTxsBytes)
+
+	// committing txs makes room again
+	sidecar.Lock()
+	require.NoError(t, sidecar.Update(1, nil, nil))
+	sidecar.Unlock()
+	require.Zero(t, sidecar.Size())
+	_, err = sidecar.AddBundle(randomTxs(3), TxInfo{DesiredHeight: 2, BundleId: 0})
+	require.NoError(t, err)
+
+	// and bundles that can't fit whole aren't started
+	sidecar.Lock()
+	sidecar.Flush()
+	sidecar.Unlock()
+	_, err = sidecar.AddBundle(randomTxs(4), TxInfo{DesiredHeight: 2, BundleId: 1})
+	assert.Equal(t, ErrSidecarIsFull{0, 3}, err)
+	assert.Zero(t, sidecar.Size())
+}
+
+func TestSidecarPinnedBundles(t *testing.T) {
+	addOrder := func(sidecar *CListPriorityTxSidecar, bundleID, order int64, pinned bool) {
+		txInfo := TxInfo{SenderID: UnknownPeerID, DesiredHeight: 1, BundleId: bundleID, BundleOrder: order, BundleSize: 3, Pinned: pinned}
+		require.NoError(t, sidecar.AddTx(randomTxs(1)[0], txInfo))
+	}
+
+	// by default, bundles with pinned txs are kept over the limit
+	config := cfg.TestSidecarConfig()
+	config.MaxBufferedOrders = 2
+	sidecar := NewCListSidecar(config, 0)
+	addOrder(sidecar, 0, 0, true)
+	addOrder(sidecar, 0, 1, false)
+	// the stale bundle is pinned, so the fresh one is evicted instead
+	addOrder(sidecar, 1, 0, false)
+	assert.Equal(t, 2, sidecar.GetCurrBundleSize(0))
+	assert.Zero(t, sidecar.GetCurrBundleSize(1))
+
+	// even if only pinned bundles are left to evict
+	addOrder(sidecar, 2, 0, true)
+	assert.Equal(t, 2, sidecar.GetCurrBundleSize(0))
+	assert.Equal(t, 1, sidecar.GetCurrBundleSize(2))
+	assert.Equal(t, 3, sidecar.Size())
+
+	// or they're evicted like any other, and counted
+	config.PinnedBundlePolicy = cfg.SidecarPinnedBundleEvict
+	config.MetricsLabel = "pinned"
+	sidecar = NewCListSidecar(config, 0, WithSidecarMetrics(PrometheusMetrics("sidecar_pinned_test")))
+	addOrder(sidecar, 0, 0, true)
+	addOrder(sidecar, 0, 1, false)
+	addOrder(sidecar, 1, 0, false)
+	assert.Zero(t, sidecar.GetCurrBundleSize(0))
+	assert.Equal(t, 1, sidecar.GetCurrBundleSize(1))
+	assert.EqualValues(t, 1, sidecarCounter(t, "sidecar_pinned_test", "pinned_evicted_sidecar_bundles", "pinned"))
+
+	// bundles without pinned txs aren't counted
+	addOrder(sidecar, 2, 0, false)
+	addOrder(sidecar, 2, 1, false)
+	assert.Zero(t, sidecar.GetCurrBundleSize(1))
+	assert.EqualValues(t, 1, sidecarCounter(t, "sidecar_pinned_test", "pinned_evicted_sidecar_bundles", "pinned"))
+}
+
+func TestSidecarFiredHeights(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	assert.Empty(t, sidecar.FiredHeights(0))
+
+	fireAuction := func(height int64) {
+		sidecar.ReapMaxTxs()
+		sidecar.Lock()
+		require.NoError(t, sidecar.Update(height, nil, nil))
+		sidecar.Unlock()
+	}
+	for height := int64(1); height <= 5; height++ {
+		fireAuction(height)
+	}
+	// reaping the same height again isn't recorded
+	sidecar.ReapMaxTxs()
+	sidecar.ReapMaxTxs()
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6}, sidecar.FiredHeights(0))
+	assert.Equal(t, []int64{5, 6}, sidecar.FiredHeights(2))
+	assert.Len(t, sidecar.FiredHeights(10), 6)
+
+	// only the most recent heights are kept
+	for height := int64(6); height < 6+firedHeightsHistory; height++ {
+		fireAuction(height)
+	}
+	heights := sidecar.FiredHeights(0)
+	require.Len(t, heights, firedHeightsHistory)
+	assert.EqualValues(t, 6, heights[0])
+	assert.EqualValues(t, 5+firedHeightsHistory, heights[len(heights)-1])
+}
+
+func TestSidecarMaxReapBytesPerHeight(t *testing.T) {
+	// single tx bundles, each tx takes 22 bytes once proto encoded: room for 5
+	config := cfg.TestSidecarConfig()
+	config.MaxReapBytesPerHeight = 5 * 22
+	sidecar := NewCListSidecar(config, 0)
+	addNumBundlesToSidecar(t, sidecar, 10, 1, UnknownPeerID)
+
+	// the cap bounds the reap even with a larger, or no, budget
+	for _, maxBytes := range []int64{-1, 5*22 + 1, 20000} {
+		memTxs, totalBytes, _ := sidecar.ReapMaxBytesMaxGas(maxBytes, -1)
+		assert.Len(t, memTxs, 5, "maxBytes %d", maxBytes)
+		assert.EqualValues(t, 5*22, totalBytes, "maxBytes %d", maxBytes)
+	}
+	assert.Len(t, sidecar.ReapMaxTxs(), 5)
+
+	// while a smaller budget still applies
+	memTxs, totalBytes, _ := sidecar.ReapMaxBytesMaxGas(2*22, -1)
+	assert.Len(t, memTxs, 2)
+	assert.EqualValues(t, 2*22, totalBytes)
+}
+
+func TestSidecarRequeue(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	txs := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0})
+
+	// only reaped bundles can be requeued
+	assert.Equal(t, ErrBundleNotReaped{0, 1}, sidecar.Requeue(0, 1, 2))
+	assert.Equal(t, ErrBundleNotReaped{1, 1}, sidecar.Requeue(1, 1, 2))
+	require.Len(t, sidecar.ReapMaxTxs(), 3)
+
+	// the proposal was rejected, so the bundle moves on to the next height
+	require.NoError(t, sidecar.Requeue(0, 1, 2))
+	assert.Empty(t, sidecar.ReapMaxTxs())
+	assert.Equal(t, 3, sidecar.Size())
+
+	// it survives the update, to be reaped again, in order
+	sidecar.Lock()
+	require.NoError(t, sidecar.Update(1, nil, nil))
+	sidecar.Unlock()
+	memTxs := sidecar.ReapMaxTxs()
+	require.Len(t, memTxs, 3)
+	for i, memTx := range memTxs {
+		assert.Equal(t, txs[i], memTx.tx)
+	}
+
+	// it can't move back to a height that already fired
+	assert.Equal(t, ErrWrongHeight{1, 2}, sidecar.Requeue(0, 2, 1))
+	// or clobber a bundle with the same id
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 3, BundleId: 0})
+	assert.Equal(t, ErrBundleExists{0, 3}, sidecar.Requeue(0, 2, 3))
+	assert.Len(t, sidecar.ReapMaxTxs(), 3)
+	assert.Equal(t, 4, sidecar.Size())
+}
+
+func TestSidecarImportBundles(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	valid, other, partial := randomTxs(2), randomTxs(3), randomTxs(1)
+	receipts := sidecar.ImportBundles([]BundleImport{
+		{Txs: valid, TxInfo: TxInfo{DesiredHeight: 1, BundleId: 0}},
+		// for a height whose auction already fired
+		{Txs: randomTxs(2), TxInfo: TxInfo{DesiredHeight: 0, BundleId: 1}},
+		// the second tx is already in the first bundle
+		{Txs: types.Txs{partial[0], valid[0]}, TxInfo: TxInfo{DesiredHeight: 1, BundleId: 2}},
+		{Txs: other, TxInfo: TxInfo{DesiredHeight: 1, BundleId: 3}},
+	})
+	require.Len(t, receipts, 4)
+
+	assert.True(t, receipts[0].Accepted())
+	assert.Equal(t, BundleReceipt{DesiredHeight: 1, BundleId: 0, NumTxs: 2, TotalBytes: 40}, receipts[0])
+
+	assert.False(t, receipts[1].Accepted())
+	assert.Equal(t, ErrBundleHeightInPast{1, 0, 0}, receipts[1].Err)
+	assert.Zero(t, receipts[1].NumTxs)
+
+	// the receipt tells what got in before the rejection
+	assert.False(t, receipts[2].Accepted())
+	assert.Equal(t, ErrTxInCache, receipts[2].Err)
+	assert.Equal(t, 1, receipts[2].NumTxs)
+
+	// a rejected bundle doesn't stop the import
+	assert.True(t, receipts[3].Accepted())
+	assert.Equal(t, 3, receipts[3].NumTxs)
+	assert.Len(t, sidecar.ReapMaxTxs(), 5)
+}
+
+func TestSidecarExecHint(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	txs := randomTxs(3)
+	hints := []int64{30, 10, 20}
+	for i, tx := range txs {
+		txInfo := TxInfo{DesiredHeight: 1, BundleId: 0, BundleOrder: int64(i), BundleSize: 3, ExecHint: hints[i]}
+		require.NoError(t, sidecar.AddTx(tx, txInfo))
+	}
+
+	// hints are carried through, without changing the reap order
+	assertHints := func(memTxs []*MempoolTx) {
+		require.Len(t, memTxs, 3)
+		for i, memTx := range memTxs {
+			assert.Equal(t, txs[i], memTx.tx)
+			assert.Equal(t, hints[i], memTx.ExecHint())
+		}
+	}
+	assertHints(sidecar.ReapMaxTxs())
+
+	// including for requeued bundles
+	require.NoError(t, sidecar.Requeue(0, 1, 2))
+	sidecar.Lock()
+	require.NoError(t, sidecar.Update(1, nil, nil))
+	sidecar.Unlock()
+	assertHints(sidecar.ReapMaxTxs())
+}
+
+func TestSidecarConcurrentAddReapUpdateFlush(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	// small limits, so adds evict each other's bundles
+	config.MaxBufferedOrders = 20
+	config.SoftMaxTxsBytes = 1000
+	sidecar := NewCListSidecar(config, 0)
+
+	const duration = 500 * time.Millisecond
+	deadline := time.Now().Add(duration)
+	var wg sync.WaitGroup
+	run := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				f()
+			}
+		}()
+	}
+
+	var height int64 // last height updated to
+	for i := 0; i < 8; i++ {
+		run(func() {
+			bundleSize := 1 + tmrand.Int63n(4)
+			txInfo := TxInfo{
+				SenderID:      uint16(tmrand.Intn(3)),
+				DesiredHeight: atomic.LoadInt64(&height) + 1 + tmrand.Int63n(2),
+				BundleId:      tmrand.Int63n(10),
+				BundleOrder:   tmrand.Int63n(bundleSize),
+				BundleSize:    bundleSize,
+			}
+			_ = sidecar.AddTx(tmrand.Bytes(20), txInfo)
+		})
+	}
+	for i := 0; i < 2; i++ {
+		run(func() { sidecar.ReapMaxTxs() })
+		run(func() { sidecar.ReapMaxBytesMaxGas(200, -1) })
+	}
+	run(func() {
+		// commit some of the reaped txs
+		committed := make(types.Txs, 0)
+		deliverTxResponses := make([]*abci.ResponseDeliverTx, 0)
+		for i, memTx := range sidecar.ReapMaxTxs() {
+			if i%2 == 0 {
+				committed = append(committed, memTx.tx)
+				deliverTxResponses = append(deliverTxResponses, &abci.ResponseDeliverTx{Code: abci.CodeTypeOK})
+			}
+		}
+		sidecar.Lock()
+		err := sidecar.Update(atomic.LoadInt64(&height)+1, committed, deliverTxResponses)
+		atomic.AddInt64(&height, 1)
+		sidecar.Unlock()
+		assert.NoError(t, err)
+		assert.NoError(t, sidecar.CheckInvariants())
+		time.Sleep(time.Millisecond)
+	})
+	run(func() {
+		time.Sleep(20 * time.Millisecond)
+		sidecar.Lock()
+		sidecar.Flush()
+		sidecar.Unlock()
+		assert.NoError(t, sidecar.CheckInvariants())
+	})
+	wg.Wait()
+
+	require.NoError(t, sidecar.CheckInvariants())
+	assert.Positive(t, atomic.LoadInt64(&height))
+}
+
+// TestSidecarConcurrentEvictSameBundle races evictions of one bundle under
+// the read lock, as two adds over a limit do: only one of them removes its txs.
+func TestSidecarConcurrentEvictSameBundle(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	var evicted int32
+	sidecar.SetBundleEvictionHook(func(BundleMeta) { atomic.AddInt32(&evicted, 1) })
+
+	info := testBundleInfo{BundleSize: 3, PeerId: 1, DesiredHeight: 1, BundleId: 0}
+	addTxToSidecar(t, sidecar, info, 0)
+	addTxToSidecar(t, sidecar, info, 1)
+	key := Key{height: 1, bundleId: 0}
+	bundle, ok := sidecar.bundles.Load(key)
+	require.True(t, ok)
+
+	var (
+		wg      sync.WaitGroup
+		claimed int32
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sidecar.updateMtx.RLock()
+			defer sidecar.updateMtx.RUnlock()
+			if sidecar.evictBundle(key, bundle.(*Bundle)) {
+				atomic.AddInt32(&claimed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, claimed)
+	assert.EqualValues(t, 1, evicted)
+	assert.Zero(t, sidecar.Size())
+	assert.Zero(t, sidecar.TxsBytes())
+	assert.EqualValues(t, 1, sidecar.PeerBundleStats()[1].EvictedBundles)
+	require.NoError(t, sidecar.CheckInvariants())
+
+	// a stale claim on a key that was since reused leaves the new bundle alone
+	addTxToSidecar(t, sidecar, info, 0)
+	assert.False(t, sidecar.removeBundle(key, bundle.(*Bundle)))
+	assert.Equal(t, 1, sidecar.GetCurrBundleSize(0))
+	assert.Equal(t, 1, sidecar.Size())
+}
+
+func TestSidecarIsBundleComplete(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	bInfo := testBundleInfo{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0}
+
+	assert.False(t, sidecar.IsBundleComplete(1, 0), "no such bundle yet")
+	for order := int64(0); order < bInfo.BundleSize; order++ {
+		assert.False(t, sidecar.IsBundleComplete(1, 0), "bundle missing order %d", order)
+		addTxToSidecar(t, sidecar, bInfo, order)
+	}
+	assert.True(t, sidecar.IsBundleComplete(1, 0))
+	assert.False(t, sidecar.IsBundleComplete(2, 0), "bundle at another height")
+	assert.Len(t, sidecar.ReapMaxTxs(), 3)
+}
+
+func TestSidecarReapBudget(t *testing.T) {
+	params := *types.DefaultConsensusParams()
+	params.Block.MaxBytes = 1000
+	params.Block.MaxGas = 100
+
+	// no fractions, the whole block
+	config := cfg.TestSidecarConfig()
+	sidecar := NewCListSidecar(config, 0)
+	maxBytes, maxGas := sidecar.ReapBudget(params)
+	assert.EqualValues(t, 1000, maxBytes)
+	assert.EqualValues(t, 100, maxGas)
+
+	config.ReapBytesFraction = 0.5
+	config.ReapGasFraction = 0.25
+	sidecar = NewCListSidecar(config, 0)
+	maxBytes, maxGas = sidecar.ReapBudget(params)
+	assert.EqualValues(t, 500, maxBytes)
+	assert.EqualValues(t, 25, maxGas)
+
+	// resolved against the params given, unlimited gas stays unlimited
+	params.Block.MaxBytes = 100
+	params.Block.MaxGas = -1
+	maxBytes, maxGas = sidecar.ReapBudget(params)
+	assert.EqualValues(t, 50, maxBytes)
+	assert.EqualValues(t, -1, maxGas)
+
+	// each single tx bundle takes 22 bytes, so only 2 fit in 50
+	addNumBundlesToSidecar(t, sidecar, 5, 1, UnknownPeerID)
+	memTxs, totalBytes, _ := sidecar.ReapForConsensusParams(params)
+	assert.Len(t, memTxs, 2)
+	assert.EqualValues(t, 44, totalBytes)
+}
+
+func TestSidecarReapExcludedPeers(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	peer2Txs := make(types.Txs, 0)
+	for bundleID := int64(0); bundleID < 4; bundleID++ {
+		// bundles 0 and 2 from peer 1, 1 and 3 from peer 2
+		bInfo := testBundleInfo{BundleSize: 2, PeerId: uint16(1 + bundleID%2), DesiredHeight: 1, BundleId: bundleID}
+		txs := createSidecarBundleAndTxs(t, sidecar, bInfo)
+		if bInfo.PeerId == 2 {
+			peer2Txs = append(peer2Txs, txs...)
+		}
+	}
+	assert.Len(t, sidecar.ReapMaxTxs(), 8)
+
+	sidecar.SetReapExcludedPeers(map[uint16]bool{1: true, 3: false})
+	memTxs := sidecar.ReapMaxTxs()
+	require.Len(t, memTxs, 4)
+	for i, memTx := range memTxs {
+		assert.Equal(t, peer2Txs[i], memTx.tx)
+	}
+	assert.Equal(t, []uint16{2}, sidecar.LastReapWinners())
+
+	// the bundles are reaped again once the peer is no longer excluded
+	sidecar.SetReapExcludedPeers(nil)
+	assert.Len(t, sidecar.ReapMaxTxs(), 8)
+}
+
+func TestSidecarReapBundles(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	sizes := []int64{4, 1, 2}
+	bundles := make([]types.Txs, len(sizes))
+	// added out of order, reaped by bundle id
+	for _, bundleID := range []int64{2, 0, 1} {
+		bundles[bundleID] = createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: sizes[bundleID], PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: bundleID})
+	}
+	// and an incomplete one, which isn't reaped
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 3}, 0)
+
+	reaped := sidecar.ReapBundles(-1, -1)
+	require.Len(t, reaped, len(bundles))
+	for i, memTxs := range reaped {
+		require.Len(t, memTxs, len(bundles[i]), "bundle %d", i)
+		for order, memTx := range memTxs {
+			assert.Equal(t, bundles[i][order], memTx.tx, "bundle %d, order %d", i, order)
+		}
+	}
+
+	// grouped the same way under a budget: each tx takes 22 bytes, so the
+	// 4 tx bundle doesn't fit, but the next ones do
+	reaped = sidecar.ReapBundles(70, -1)
+	require.Len(t, reaped, 2)
+	assert.Len(t, reaped[0], 1)
+	assert.Len(t, reaped[1], 2)
+
+	// appending to a bundle doesn't touch the next one
+	reaped[0] = append(reaped[0], reaped[0][0])
+	assert.Equal(t, bundles[2][0], reaped[1][0].tx)
+}
+
+func TestSidecarReapAndSeal(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	txs := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0})
+	// the last order of another bundle is still missing
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1}, 0)
+
+	_, err := sidecar.ReapAndSeal(2, -1, -1)
+	assert.Equal(t, ErrWrongHeight{2, 1}, err)
+
+	memTxs, err := sidecar.ReapAndSeal(1, -1, -1)
+	require.NoError(t, err)
+	require.Len(t, memTxs, 2)
+	assert.Equal(t, txs[0], memTxs[0].tx)
+	assert.Equal(t, txs[1], memTxs[1].tx)
+
+	// neither new bundles nor missing orders for the height get in anymore
+	err = sidecar.AddTx(types.Tx("order 1"), TxInfo{DesiredHeight: 1, BundleId: 1, BundleOrder: 1, BundleSize: 2})
+	assert.Equal(t, ErrWrongHeight{1, 2}, err)
+	_, err = sidecar.AddBundle(randomTxs(1), TxInfo{DesiredHeight: 1, BundleId: 2})
+	assert.Equal(t, ErrWrongHeight{1, 2}, err)
+	assert.Len(t, sidecar.ReapMaxTxs(), 2)
+
+	// while the next height is still open, and stays so once it's the auction
+	// height
+	laterTxs := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 2, BundleId: 0})
+	sidecar.Lock()
+	require.NoError(t, sidecar.Update(1, txs, abciResponses(len(txs), abci.CodeTypeOK)))
+	sidecar.Unlock()
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 2, BundleId: 1})
+	memTxs = sidecar.ReapMaxTxs()
+	require.Len(t, memTxs, 2)
+	assert.Equal(t, laterTxs[0], memTxs[0].tx)
+}
+
+func TestSidecarMaxBundlesPerReap(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.MaxBundlesPerReap = 2
+	sidecar := NewCListSidecar(config, 0)
+	bundles := make([]types.Txs, 5)
+	for i := range bundles {
+		bundles[i] = createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: int64(i)})
+	}
+
+	reaped := sidecar.ReapBundles(-1, -1)
+	require.Len(t, reaped, 2)
+	for i, memTxs := range reaped {
+		require.Len(t, memTxs, 2)
+		assert.Equal(t, bundles[i][0], memTxs[0].tx)
+	}
+	assert.Len(t, sidecar.ReapMaxTxs(), 4)
+
+	// whatever budget is left
+	memTxs, _, _ := sidecar.ReapMaxBytesMaxGas(1000, -1)
+	assert.Len(t, memTxs, 4)
+
+	// paging through the reap stops at the cap too, even a page at a time
+	var paged []*MempoolTx
+	cursor := ReapCursor{}
+	for !cursor.Done() {
+		var page []*MempoolTx
+		page, cursor = sidecar.ReapPage(cursor, 1)
+		paged = append(paged, page...)
+	}
+	require.Len(t, paged, 4)
+	assert.Equal(t, bundles[1][1], paged[3].tx)
+
+	// partial bundles count towards the cap too
+	config.AllowPartialBundles = true
+	sidecar = NewCListSidecar(config, 0)
+	complete := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 0})
+	first := addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 1}, 0)
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 2}, 0)
+	assert.Equal(t, types.Txs{complete[0], first}, sidecar.ReapTxs(-1, -1))
+	audit := sidecar.LastReapAudit()
+	require.Len(t, audit, 3)
+	assert.Equal(t, ReapDecision{BundleMeta: BundleMeta{DesiredHeight: 1, BundleId: 2, SenderID: 1}, Reason: ReapReasonMaxBundles}, audit[2])
+}
+
+func TestSidecarUpdateResponseLengthMismatch(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	txs := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0})
+
+	sidecar.Lock()
+	defer sidecar.Unlock()
+	err := sidecar.Update(1, txs, abciResponses(1, abci.CodeTypeOK))
+	assert.Equal(t, ErrResponseLengthMismatch{2, 1}, err)
+	assert.Equal(t, SidecarCodeResponseLengthMismatch, SidecarErrorCode(err))
+	err = sidecar.Update(1, txs, nil)
+	assert.Equal(t, ErrResponseLengthMismatch{2, 0}, err)
+
+	// nothing was updated
+	assert.EqualValues(t, 1, sidecar.HeightForFiringAuction())
+	assert.Equal(t, 2, sidecar.Size())
+
+	require.NoError(t, sidecar.Update(1, txs, abciResponses(2, abci.CodeTypeOK)))
+	assert.Zero(t, sidecar.Size())
+}
+
+func TestSidecarAuctionToCommitMetric(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+	metrics := PrometheusMetrics("sidecar_auction_to_commit_test")
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0, WithSidecarMetrics(metrics), WithSidecarClock(clock))
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0})
+
+	// the auction for height 1 fires on the first reap, later ones don't restart it
+	require.Len(t, sidecar.ReapMaxTxs(), 2)
+	now = now.Add(time.Second)
+	sidecar.ReapMaxTxs()
+	now = now.Add(2 * time.Second)
+	require.NoError(t, sidecar.Update(1, types.Txs{}, abciResponses(0, abci.CodeTypeOK)))
+
+	count, sum := sidecarHistogram(t, "sidecar_auction_to_commit_test", "sidecar_auction_to_commit_seconds", "")
+	assert.EqualValues(t, 1, count)
+	assert.InDelta(t, 3, sum, 1e-9)
+
+	// no auction fired for height 2
+	now = now.Add(time.Second)
+	require.NoError(t, sidecar.Update(2, types.Txs{}, abciResponses(0, abci.CodeTypeOK)))
+	count, _ = sidecarHistogram(t, "sidecar_auction_to_commit_test", "sidecar_auction_to_commit_seconds", "")
+	assert.EqualValues(t, 1, count)
+}
+
+// sidecarHistogram returns the sample count and sum of the histogram name of
+// the sidecar labeled sidecarLabel, from metrics built with namespace.
+func sidecarHistogram(t *testing.T, namespace, name, sidecarLabel string) (uint64, float64) {
+	families, err := stdprometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != namespace+"_"+MetricsSubsystem+"_"+name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == SidecarMetricsLabel && label.GetValue() == sidecarLabel {
+					histogram := metric.GetHistogram()
+					return histogram.GetSampleCount(), histogram.GetSampleSum()
+				}
+			}
+		}
+	}
+	t.Fatalf("no %s metric for sidecar %q", name, sidecarLabel)
+	return 0, 0
+}
+
+func TestSidecarRemoveBundlesForHeight(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0},
+		{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1},
+		{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 4},
+		{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 2, BundleId: 0},
+	}, UnknownPeerID)
+	// incomplete bundles are removed too
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 2}, 0)
+	require.Equal(t, 9, sidecar.Size())
+
+	assert.Equal(t, 4, sidecar.RemoveBundlesForHeight(1))
+	assert.Equal(t, 1, sidecar.NumBundles())
+	assert.Equal(t, 2, sidecar.Size())
+	assert.EqualValues(t, 40, sidecar.TxsBytes())
+	assert.Empty(t, sidecar.ReapMaxTxs())
+	require.NoError(t, sidecar.CheckInvariants())
+
+	assert.Zero(t, sidecar.RemoveBundlesForHeight(1))
+	assert.Zero(t, sidecar.RemoveBundlesForHeight(3))
+	assert.Equal(t, 1, sidecar.RemoveBundlesForHeight(2))
+	assert.Zero(t, sidecar.Size())
+	assert.Zero(t, sidecar.TxsBytes())
+}
+
+func TestSidecarBundleOrderBeyondSize(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	txs := randomTxs(5)
+	for order, tx := range txs[:3] {
+		require.NoError(t, sidecar.AddTx(tx, TxInfo{DesiredHeight: 1, BundleId: 0, BundleOrder: int64(order), BundleSize: 3}))
+	}
+
+	// an extra order, declaring the bundle's size or a larger one
+	err := sidecar.AddTx(txs[3], TxInfo{DesiredHeight: 1, BundleId: 0, BundleOrder: 3, BundleSize: 3})
+	assert.Equal(t, ErrBundleOrderBeyondSize{0, 1, 3, 3}, err)
+	assert.Equal(t, SidecarCodeBundleOrderBeyondSize, SidecarErrorCode(err))
+	err = sidecar.AddTx(txs[4], TxInfo{DesiredHeight: 1, BundleId: 0, BundleOrder: 3, BundleSize: 4})
+	assert.Equal(t, ErrBundleOrderBeyondSize{0, 1, 3, 3}, err)
+
+	bundle, ok := sidecar.loadBundle(1, 0)
+	require.True(t, ok)
+	assert.EqualValues(t, 3, bundle.enforcedSize)
+	assert.EqualValues(t, 3, atomic.LoadInt64(&bundle.currSize))
+	memTxs := sidecar.ReapMaxTxs()
+	require.Len(t, memTxs, 3)
+	for i, memTx := range memTxs {
+		assert.Equal(t, txs[i], memTx.tx)
+	}
+	require.NoError(t, sidecar.CheckInvariants())
+}
+
+func TestSidecarEvictionHook(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.NamespaceBundlesBySender = true
+	sidecar := NewCListSidecar(config, 0)
+	var evicted []BundleMeta
+	sidecar.SetBundleEvictionHook(func(meta BundleMeta) { evicted = append(evicted, meta) })
+
+	// evictions are notified as they happen
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 3, DesiredHeight: 1, BundleId: 9}, 0)
+	sidecar.OnPeerDisconnect(3)
+	assert.Equal(t, []BundleMeta{{DesiredHeight: 1, BundleId: 9, SenderID: 3}}, evicted)
+
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 1, PeerId: 2, DesiredHeight: 2, BundleId: 0},
+		{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 3},
+		{BundleSize: 2, PeerId: 2, DesiredHeight: 1, BundleId: 1},
+		{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 1},
+		{BundleSize: 1, PeerId: 1, DesiredHeight: 2, BundleId: 0},
+	}, UnknownPeerID)
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 0}, 1)
+
+	// flushed bundles are notified by height, bundle id, then sender
+	evicted = nil
+	sidecar.Lock()
+	sidecar.Flush()
+	sidecar.Unlock()
+	assert.Equal(t, []BundleMeta{
+		{DesiredHeight: 1, BundleId: 0, SenderID: 1},
+		{DesiredHeight: 1, BundleId: 1, SenderID: 1},
+		{DesiredHeight: 1, BundleId: 1, SenderID: 2},
+		{DesiredHeight: 1, BundleId: 3, SenderID: 1},
+		{DesiredHeight: 2, BundleId: 0, SenderID: 1},
+		{DesiredHeight: 2, BundleId: 0, SenderID: 2},
+	}, evicted)
+}
+
+func TestSidecarAllowPartialBundles(t *testing.T) {
+	for _, allow := range []bool{false, true} {
+		config := cfg.TestSidecarConfig()
+		config.AllowPartialBundles = allow
+		sidecar := NewCListSidecar(config, 0)
+		// orders 0 and 1 of 3
+		partial := testBundleInfo{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0}
+		prefix := types.Txs{addTxToSidecar(t, sidecar, partial, 0), addTxToSidecar(t, sidecar, partial, 1)}
+		complete := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1})
+		// orders 0 and 2 of 3, so only order 0 is contiguous
+		gapped := testBundleInfo{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 2}
+		gappedPrefix := types.Txs{addTxToSidecar(t, sidecar, gapped, 0)}
+		addTxToSidecar(t, sidecar, gapped, 2)
+
+		// complete bundles come first, even past a partial bundle's id
+		assert.Equal(t, complete, sidecar.ReapTxs(44, -1), "allow %v", allow)
+		if !allow {
+			assert.Equal(t, complete, sidecar.ReapTxs(-1, -1))
+			continue
+		}
+		// partial bundles only fill leftover space, as much of them as fits
+		assert.Equal(t, append(append(types.Txs{}, complete...), prefix[0]), sidecar.ReapTxs(66, -1))
+		assert.Equal(t, append(append(types.Txs{}, complete...), prefix...), sidecar.ReapTxs(100, -1))
+		expected := append(append(append(types.Txs{}, complete...), prefix...), gappedPrefix...)
+		assert.Equal(t, expected, sidecar.ReapTxs(-1, -1))
+		assert.Len(t, sidecar.ReapBundles(-1, -1), 3)
+	}
+}
+
+func TestSidecarMinOrdersToReap(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.AllowPartialBundles = true
+	sidecar := NewCListSidecar(config, 0)
+	txs := randomTxs(3)
+	addOrder := func(tx types.Tx, bundleID, bundleOrder int64) {
+		txInfo := TxInfo{DesiredHeight: 1, BundleId: bundleID, BundleOrder: bundleOrder, BundleSize: 3, MinOrdersToReap: 2}
+		require.NoError(t, sidecar.AddTx(tx, txInfo))
+	}
+
+	// only order 0 of bundle 0 arrived, orders 0 and 1 of bundle 1
+	addOrder(txs[0], 0, 0)
+	addOrder(txs[1], 1, 0)
+	addOrder(txs[2], 1, 1)
+	assert.Equal(t, txs[1:], sidecar.ReapTxs(-1, -1))
+	assert.Equal(t, []ReapDecision{
+		{BundleMeta: BundleMeta{DesiredHeight: 1, BundleId: 0}, Reason: ReapReasonMinOrders},
+		{BundleMeta: BundleMeta{DesiredHeight: 1, BundleId: 1}, Included: true, Reason: ReapReasonPartial},
+	}, sidecar.LastReapAudit())
+	// a prefix cut short by the budget counts too
+	assert.Empty(t, sidecar.ReapTxs(types.ComputeProtoSizeForTxs(txs[1:2]), -1))
+	assert.Contains(t, sidecar.LastReapAudit(), ReapDecision{BundleMeta: BundleMeta{DesiredHeight: 1, BundleId: 1}, Reason: ReapReasonMinOrders})
+}
+
+func TestSidecarAddTxRacingUpdate(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	const numTxs = 200
+	txs := randomTxs(numTxs)
+	errs := make([]error, numTxs)
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := range txs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = sidecar.AddTx(txs[i], TxInfo{DesiredHeight: 1, BundleId: int64(i), BundleSize: 1})
+		}(i)
+	}
+	close(start)
+	sidecar.Lock()
+	require.NoError(t, sidecar.Update(1, nil, nil))
+	sidecar.Unlock()
+	wg.Wait()
+
+	// txs added before the Update were dropped by it, later ones were rejected
+	for i, err := range errs {
+		if err != nil {
+			assert.Equal(t, ErrBundleHeightInPast{int64(i), 1, 1}, err)
+		}
+	}
+	assert.Zero(t, sidecar.Size())
+	assert.Zero(t, sidecar.NumBundles())
+	require.NoError(t, sidecar.CheckInvariants())
+}
+
+func TestSidecarSimulateReap(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.NamespaceBundlesBySender = true
+	sidecar := NewCListSidecar(config, 0)
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 0},
+		{BundleSize: 4, PeerId: 2, DesiredHeight: 1, BundleId: 1},
+		{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 2},
+	}, UnknownPeerID)
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 3, DesiredHeight: 1, BundleId: 3}, 0)
+
+	// the second bundle doesn't fit in 4 txs' worth of bytes
+	plan := sidecar.SimulateReap(88, -1)
+	assert.Equal(t, ReapPlan{
+		Bundles: []PlannedBundle{
+			{BundleMeta: BundleMeta{DesiredHeight: 1, BundleId: 0, SenderID: 1}, NumTxs: 2},
+			{BundleMeta: BundleMeta{DesiredHeight: 1, BundleId: 2, SenderID: 1}, NumTxs: 1},
+		},
+		NumTxs:     3,
+		TotalBytes: 66,
+	}, plan)
+	// no auction fired
+	assert.Empty(t, sidecar.FiredHeights(0))
+	assert.Equal(t, plan, sidecar.SimulateReap(88, -1))
+
+	// the real reap matches the plan
+	var reaped []PlannedBundle
+	memTxs, totalBytes, totalGas := sidecar.reapMaxBytesMaxGasInto(nil, 88, -1, func(bundle *Bundle, memTxs []*MempoolTx) {
+		reaped = append(reaped, PlannedBundle{BundleMeta: bundleMeta(bundle), NumTxs: len(memTxs)})
+	})
+	assert.Equal(t, plan.Bundles, reaped)
+	assert.Len(t, memTxs, plan.NumTxs)
+	assert.Equal(t, plan.TotalBytes, totalBytes)
+	assert.Equal(t, plan.TotalGas, totalGas)
+
+	// partial bundles are planned too
+	sidecar.config.AllowPartialBundles = true
+	plan = sidecar.SimulateReap(-1, -1)
+	require.Len(t, plan.Bundles, 4)
+	assert.Equal(t, PlannedBundle{BundleMeta: BundleMeta{DesiredHeight: 1, BundleId: 3, SenderID: 3}, NumTxs: 1, Partial: true}, plan.Bundles[3])
+	assert.Len(t, sidecar.ReapMaxTxs(), plan.NumTxs)
+
+	// and capped like the real reap, which the plan still matches
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 3, DesiredHeight: 1, BundleId: 4}, 0)
+	sidecar.config.MaxBundlesPerReap = 4
+	plan = sidecar.SimulateReap(-1, -1)
+	require.Len(t, plan.Bundles, 4)
+	assert.Equal(t, BundleMeta{DesiredHeight: 1, BundleId: 3, SenderID: 3}, plan.Bundles[3].BundleMeta)
+	reaped = nil
+	memTxs, totalBytes, totalGas = sidecar.reapMaxBytesMaxGasInto(nil, -1, -1, func(bundle *Bundle, memTxs []*MempoolTx) {
+		reaped = append(reaped, PlannedBundle{BundleMeta: bundleMeta(bundle), NumTxs: len(memTxs), Partial: !bundle.isComplete()})
+	})
+	assert.Equal(t, plan.Bundles, reaped)
+	assert.Len(t, memTxs, plan.NumTxs)
+	assert.Equal(t, plan.TotalBytes, totalBytes)
+	assert.Equal(t, plan.TotalGas, totalGas)
+}
+
+func TestSidecarReapWithMustInclude(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 0},
+		{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 1},
+		{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 2},
+	}, UnknownPeerID)
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 3}, 0)
+	bundleTxs := func(bundleId int64) []types.Tx {
+		bundle, ok := sidecar.loadBundle(1, bundleId)
+		require.True(t, ok)
+		txs := make([]types.Tx, 0, bundle.enforcedSize)
+		for bundleOrder := int64(0); bundleOrder < bundle.enforcedSize; bundleOrder++ {
+			scTx, ok := bundle.orderedTxsMap.Load(bundleOrder)
+			require.True(t, ok)
+			txs = append(txs, scTx.(*SidecarTx).tx)
+		}
+		return txs
+	}
+
+	// missing, incomplete, or too big for the budget: nothing is reaped
+	for _, mustIDs := range [][]int64{{5}, {3}, {2, 0, 1}} {
+		memTxs, err := sidecar.ReapWithMustInclude(mustIDs, 88, -1)
+		assert.IsType(t, ErrMustIncludeBundle{}, err, "mustIDs %v", mustIDs)
+		assert.Equal(t, SidecarCodeMustIncludeBundle, SidecarErrorCode(err))
+		assert.Empty(t, memTxs)
+	}
+	assert.Empty(t, sidecar.FiredHeights(0))
+
+	// the must-include bundles come first, in the given order, then bundle 0
+	// doesn't fit in the 4 txs' worth of bytes left
+	memTxs, err := sidecar.ReapWithMustInclude([]int64{2, 1, 2}, 88, -1)
+	require.NoError(t, err)
+	want := append(bundleTxs(2), bundleTxs(1)...)
+	require.Len(t, memTxs, len(want))
+	for i, memTx := range memTxs {
+		assert.Equal(t, want[i], memTx.tx)
+	}
+	assert.Equal(t, []int64{1}, sidecar.FiredHeights(0))
+
+	// with room left, the others follow in reap order
+	memTxs, err = sidecar.ReapWithMustInclude([]int64{2}, -1, -1)
+	require.NoError(t, err)
+	want = append(bundleTxs(2), append(bundleTxs(0), bundleTxs(1)...)...)
+	require.Len(t, memTxs, len(want))
+	for i, memTx := range memTxs {
+		assert.Equal(t, want[i], memTx.tx)
+	}
+}
+
+func TestSidecarBundleOrderBase(t *testing.T) {
+	for _, base := range []int{0, 1} {
+		config := cfg.TestSidecarConfig()
+		config.BundleOrderBase = base
+		sidecar := NewCListSidecar(config, 0)
+		txs := randomTxs(3)
+		errs := make([]error, len(txs))
+		for i, tx := range txs {
+			errs[i] = sidecar.AddTx(tx, TxInfo{DesiredHeight: 1, BundleId: 0, BundleOrder: int64(i + 1), BundleSize: 3})
+		}
+
+		if base == 1 {
+			require.Equal(t, []error{nil, nil, nil}, errs)
+			assert.True(t, sidecar.IsBundleComplete(1, 0))
+			memTxs := sidecar.ReapMaxTxs()
+			require.Len(t, memTxs, 3)
+			for i, memTx := range memTxs {
+				assert.Equal(t, txs[i], memTx.tx)
+			}
+			// and gossiped with the orders they were sent with
+			first := sidecar.TxsFront().Value.(*SidecarTx)
+			require.Equal(t, txs[0], first.tx)
+			assert.EqualValues(t, 1, newSidecarTxMessage(first, 1).BundleOrder)
+			continue
+		}
+		// counting from 0, the last order is past the bundle, and the first is missing
+		assert.Equal(t, []error{nil, nil, ErrBundleOrderBeyondSize{0, 1, 3, 3}}, errs)
+		assert.False(t, sidecar.IsBundleComplete(1, 0))
+		assert.Empty(t, sidecar.ReapMaxTxs())
+	}
+}
+
+func TestSidecarBundleSenderMismatch(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	txs := randomTxs(2)
+	require.NoError(t, sidecar.AddTx(txs[0], TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 0, BundleOrder: 0, BundleSize: 2}))
+
+	err := sidecar.AddTx(txs[1], TxInfo{SenderID: 2, DesiredHeight: 1, BundleId: 0, BundleOrder: 1, BundleSize: 2})
+	assert.Equal(t, ErrBundleSenderMismatch{0, 1, 2, 1}, err)
+	assert.Equal(t, SidecarCodeBundleSenderMismatch, SidecarErrorCode(err))
+	assert.Equal(t, 1, sidecar.Size())
+	assert.EqualValues(t, 1, sidecar.PeerBundleStats()[2].RejectedTxs)
+
+	// the bundle is left alone, and its sender can still complete it with the tx
+	require.NoError(t, sidecar.AddTx(txs[1], TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 0, BundleOrder: 1, BundleSize: 2}))
+	assert.True(t, sidecar.IsBundleComplete(1, 0))
+	require.NoError(t, sidecar.CheckInvariants())
+}
+
+func TestSidecarBundleSenderMismatchKeepsBundle(t *testing.T) {
+	app := &rejectingApp{reject: types.Tx("bad")}
+	appConn, err := proxy.NewLocalClientCreator(app).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop() // nolint:errcheck
+
+	config := cfg.TestSidecarConfig()
+	config.StrictMode = true
+	sidecar := NewCListSidecar(config, 0, WithSidecarProxyAppConn(appConn))
+	require.NoError(t, sidecar.AddTx(types.Tx("good"), TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 0, BundleOrder: 0, BundleSize: 2}))
+
+	// neither a wrong size nor a rejected tx from another peer evicts it
+	err = sidecar.AddTx(types.Tx("resized"), TxInfo{SenderID: 2, DesiredHeight: 1, BundleId: 0, BundleOrder: 1, BundleSize: 3})
+	assert.Equal(t, ErrBundleSenderMismatch{0, 1, 2, 1}, err)
+	err = sidecar.AddTx(app.reject, TxInfo{SenderID: 2, DesiredHeight: 1, BundleId: 0, BundleOrder: 1, BundleSize: 2})
+	assert.Equal(t, ErrBundleSenderMismatch{0, 1, 2, 1}, err)
+	assert.Equal(t, 1, sidecar.GetCurrBundleSize(0))
+	assert.Equal(t, 1, sidecar.Size())
+	assert.Zero(t, sidecar.PeerBundleStats()[2].StrictEvictions)
+	assert.Zero(t, sidecar.PeerBundleStats()[1].EvictedBundles)
+
+	// and its sender can still complete it
+	require.NoError(t, sidecar.AddTx(types.Tx("fine"), TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 0, BundleOrder: 1, BundleSize: 2}))
+	assert.True(t, sidecar.IsBundleComplete(1, 0))
+	require.NoError(t, sidecar.CheckInvariants())
+}
+
+func TestSidecarStarvedBundles(t *testing.T) {
+	metrics := PrometheusMetrics("sidecar_starved_test")
+
+	// evicted once it doesn't fit in 3 reaps in a row
+	config := cfg.TestSidecarConfig()
+	config.StarvedBundleThreshold = 3
+	sidecar := NewCListSidecar(config, 0, WithSidecarMetrics(metrics))
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 3, PeerId: 1, DesiredHeight: 1, BundleId: 0},
+		{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 1},
+	}, 1)
+	for i := 0; i < 2; i++ {
+		memTxs, _, _ := sidecar.ReapMaxBytesMaxGas(44, -1)
+		require.Len(t, memTxs, 1)
+	}
+	assert.Equal(t, 4, sidecar.Size())
+	memTxs, _, _ := sidecar.ReapMaxBytesMaxGas(44, -1)
+	require.Len(t, memTxs, 1)
+	assert.Equal(t, 1, sidecar.Size())
+	assert.EqualValues(t, 1, sidecar.PeerBundleStats()[1].EvictedBundles)
+	assert.EqualValues(t, 1, sidecarCounter(t, "sidecar_starved_test", "starved_sidecar_bundles", ""))
+
+	// or reaped ahead of the others once it doesn't fit in 2 reaps in a row
+	config = cfg.TestSidecarConfig()
+	config.StarvedBundleThreshold = 2
+	config.StarvedBundlePolicy = cfg.SidecarStarvedBundlePrioritize
+	sidecar = NewCListSidecar(config, 0, WithSidecarMetrics(metrics))
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 0})
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 1})
+	starved := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 2})
+	for i := 0; i < 2; i++ {
+		memTxs, _, _ := sidecar.ReapMaxBytesMaxGas(66, -1)
+		require.Len(t, memTxs, 2)
+	}
+	assert.EqualValues(t, 2, sidecarCounter(t, "sidecar_starved_test", "starved_sidecar_bundles", ""))
+	memTxs, _, _ = sidecar.ReapMaxBytesMaxGas(66, -1)
+	require.Len(t, memTxs, 3)
+	assert.Equal(t, starved[0], memTxs[0].tx)
+	assert.Equal(t, starved[1], memTxs[1].tx)
+	assert.Equal(t, 4, sidecar.Size())
+
+	// a bundle that's reaped starts over
+	bundle, ok := sidecar.loadBundle(1, 2)
+	require.True(t, ok)
+	assert.Zero(t, atomic.LoadInt32(&bundle.budgetSkips))
+}
+
+func TestSidecarStarvedBundleAlreadyEvicted(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.StarvedBundleThreshold = 1
+	sidecar := NewCListSidecar(config, 0)
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 0})
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 1})
+	key := Key{height: 1, bundleId: 0}
+	bundle, ok := sidecar.bundles.Load(key)
+	require.True(t, ok)
+
+	// a reap skipped the bundle, but it was evicted before the reap got to
+	// evicting it for starving: it's only evicted once
+	selection := reapSelection{skipped: []selectedBundle{{key: key, bundle: bundle.(*Bundle)}}}
+	sidecar.updateMtx.RLock()
+	require.True(t, sidecar.evictBundle(key, bundle.(*Bundle)))
+	sidecar.countBudgetSkips(selection)
+	sidecar.updateMtx.RUnlock()
+
+	assert.EqualValues(t, 1, sidecar.PeerBundleStats()[1].EvictedBundles)
+	assert.Equal(t, 1, sidecar.Size())
+	require.NoError(t, sidecar.CheckInvariants())
+}
+
+func TestSidecarSyncing(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	txs := randomTxs(2)
+	txInfo := TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 0, BundleSize: 1}
+
+	sidecar.SetSyncing(true)
+	assert.True(t, sidecar.IsSyncing())
+	assert.Equal(t, ErrNodeSyncing, sidecar.AddTx(txs[0], txInfo))
+	_, err := sidecar.AddBundle(txs, TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 1})
+	assert.Equal(t, ErrNodeSyncing, err)
+	assert.Zero(t, sidecar.Size())
+	// not the peer's fault
+	assert.Zero(t, sidecar.PeerBundleStats()[1].RejectedTxs)
+
+	// once synced, the same txs are accepted
+	sidecar.SetSyncing(false)
+	assert.False(t, sidecar.IsSyncing())
+	require.NoError(t, sidecar.AddTx(txs[0], txInfo))
+	_, err = sidecar.AddBundle(txs[1:], TxInfo{SenderID: 1, DesiredHeight: 1, BundleId: 1})
+	require.NoError(t, err)
+	assert.Len(t, sidecar.ReapMaxTxs(), 2)
+}
+
+func TestSidecarLastReapAudit(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.MaxBundlesPerReap = 2
+	sidecar := NewCListSidecar(config, 0)
+	assert.Empty(t, sidecar.LastReapAudit())
+
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 0})
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 1}, 0)
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 3, PeerId: 1, DesiredHeight: 1, BundleId: 2})
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: 2, DesiredHeight: 1, BundleId: 3})
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 4})
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 5})
+	sidecar.SetReapExcludedPeers(map[uint16]bool{2: true})
+
+	decision := func(bundleID int64, sender uint16, included bool, reason string) ReapDecision {
+		meta := BundleMeta{DesiredHeight: 1, BundleId: bundleID, SenderID: sender}
+		return ReapDecision{BundleMeta: meta, Included: included, Reason: reason}
+	}
+
+	// room for bundle 0 and 4 only: 2 doesn't fit and the cap is hit at 5
+	memTxs, _, _ := sidecar.ReapMaxBytesMaxGas(66, -1)
+	require.Len(t, memTxs, 2)
+	assert.Equal(t, []ReapDecision{
+		decision(0, 1, true, ReapReasonIncluded),
+		decision(1, 1, false, ReapReasonIncomplete),
+		decision(2, 1, false, ReapReasonOverBudget),
+		decision(3, 2, false, ReapReasonExcludedPeer),
+		decision(4, 1, true, ReapReasonIncluded),
+		decision(5, 1, false, ReapReasonMaxBundles),
+	}, sidecar.LastReapAudit())
+
+	// a must-include bundle is decided on first, and a simulated reap isn't
+	// audited
+	_, err := sidecar.ReapWithMustInclude([]int64{2}, 66, -1)
+	require.NoError(t, err)
+	sidecar.SimulateReap(-1, -1)
+	assert.Equal(t, []ReapDecision{
+		decision(2, 1, true, ReapReasonMustInclude),
+		decision(0, 1, false, ReapReasonOverBudget),
+		decision(1, 1, false, ReapReasonIncomplete),
+		decision(3, 2, false, ReapReasonExcludedPeer),
+		decision(4, 1, false, ReapReasonOverBudget),
+		decision(5, 1, false, ReapReasonOverBudget),
+	}, sidecar.LastReapAudit())
+}
+
+func TestNewCListSidecarInvalidConfig(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.MaxSidecarTxs = 2
+	config.MaxBufferedOrders = 4
+	sidecar, err := NewCListSidecarE(config, 0)
+	assert.EqualError(t, err,
+		"invalid sidecar config: max_buffered_orders (4) can't be more than max_sidecar_txs (2), which would reject orders first")
+	assert.Nil(t, sidecar)
+
+	config.MaxBufferedOrders = 2
+	sidecar, err = NewCListSidecarE(config, 0)
+	require.NoError(t, err)
+	assert.NotNil(t, sidecar)
+}
+
+func TestSidecarForceComplete(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	sidecar := NewCListSidecar(config, 0)
+	bInfo := testBundleInfo{BundleSize: 3, PeerId: 1, DesiredHeight: 1, BundleId: 0}
+	tx0 := addTxToSidecar(t, sidecar, bInfo, 0)
+	tx2 := addTxToSidecar(t, sidecar, bInfo, 2)
+	fill := randomTxs(1)[0]
+
+	// only when allowed
+	err := sidecar.ForceComplete(1, 0, map[int64]types.Tx{1: fill})
+	assert.Equal(t, ErrForceComplete{0, 1, "allow_force_complete isn't set"}, err)
+	config.AllowForceComplete = true
+
+	assert.Equal(t, ErrForceComplete{1, 1, "no such bundle"}, sidecar.ForceComplete(1, 1, map[int64]types.Tx{}))
+	err = sidecar.ForceComplete(1, 0, map[int64]types.Tx{0: fill})
+	assert.Equal(t, ErrForceComplete{0, 1, "no fill tx for missing order 1"}, err)
+	assert.Equal(t, 2, sidecar.Size())
+	assert.Empty(t, sidecar.ReapMaxTxs())
+
+	// the gap is filled, and the orders the bundle holds are kept
+	require.NoError(t, sidecar.ForceComplete(1, 0, map[int64]types.Tx{0: randomTxs(1)[0], 1: fill}))
+	assert.True(t, sidecar.IsBundleComplete(1, 0))
+	memTxs := sidecar.ReapMaxTxs()
+	require.Len(t, memTxs, 3)
+	assert.Equal(t, types.Txs{tx0, fill, tx2}, types.Txs{memTxs[0].tx, memTxs[1].tx, memTxs[2].tx})
+}
+
+func TestValidateBundle(t *testing.T) {
+	txs := randomTxs(3)
+	infos := func(orders ...int64) []TxInfo {
+		infos := make([]TxInfo, len(orders))
+		for i, order := range orders {
+			infos[i] = TxInfo{DesiredHeight: 1, BundleId: 0, BundleOrder: order, BundleSize: 3}
+		}
+		return infos
+	}
+	withInfo := func(infos []TxInfo, i int, update func(*TxInfo)) []TxInfo {
+		update(&infos[i])
+		return infos
+	}
+
+	tests := []struct {
+		name   string
+		txs    types.Txs
+		infos  []TxInfo
+		config func(*cfg.SidecarConfig)
+		err    error
+		// whether AddTx returns err as well, for the first tx that fails
+		matchesAddTx bool
+	}{
+		{"valid", txs, infos(0, 1, 2), nil, nil, true},
+		{"valid out of order", txs, infos(2, 0, 1), nil, nil, true},
+		{"no txs", nil, nil, nil, ErrTxMalformedForBundle{}, false},
+		{"fewer infos than txs", txs, infos(0, 1), nil, ErrTxMalformedForBundle{0, 3, 1, 0}, false},
+		{"missing order", txs[:2], infos(0, 2), nil, ErrTxMalformedForBundle{0, 3, 1, 1}, false},
+		{"order past size", txs, infos(0, 1, 3), nil, ErrBundleOrderBeyondSize{0, 1, 3, 3}, true},
+		{
+			"order past declared size", txs, withInfo(infos(0, 1, 2), 2, func(info *TxInfo) { info.BundleOrder, info.BundleSize = 3, 4 }),
+			nil, ErrBundleOrderBeyondSize{0, 1, 3, 3}, true,
+		},
+		{"negative order", txs, infos(0, -1, 2), nil, ErrTxMalformedForBundle{0, 3, 1, -1}, true},
+		{"repeated order", txs, infos(0, 1, 1), nil, ErrTxMalformedForBundle{0, 3, 1, 1}, false},
+		{"repeated tx", types.Txs{txs[0], txs[1], txs[0]}, infos(0, 1, 2), nil, ErrTxInCache, true},
+		// AddTx sees the extra tx as conflicting with the one at order 0
+		{"too many txs", append(randomTxs(1), txs...), infos(0, 1, 2, 0), nil, ErrBundleFull{0, 3}, false},
+		{
+			"inconsistent size", txs, withInfo(infos(0, 1, 2), 1, func(info *TxInfo) { info.BundleSize = 2 }),
+			nil, ErrTxMalformedForBundle{0, 2, 1, 1}, true,
+		},
+		{
+			"inconsistent height", txs, withInfo(infos(0, 1, 2), 2, func(info *TxInfo) { info.DesiredHeight = 2 }),
+			nil, ErrTxMalformedForBundle{0, 3, 2, 2}, false,
+		},
+		{
+			"inconsistent id", txs, withInfo(infos(0, 1, 2), 2, func(info *TxInfo) { info.BundleId = 1 }),
+			nil, ErrTxMalformedForBundle{1, 3, 1, 2}, false,
+		},
+		{
+			"other sender, without namespacing", txs, withInfo(infos(0, 1, 2), 2, func(info *TxInfo) { info.SenderID = 1 }),
+			nil, ErrBundleSenderMismatch{0, 1, 1, 0}, true,
+		},
+		{
+			"other sender, with namespacing", txs, withInfo(infos(0, 1, 2), 2, func(info *TxInfo) { info.SenderID = 1 }),
+			func(config *cfg.SidecarConfig) { config.NamespaceBundlesBySender = true },
+			ErrTxMalformedForBundle{0, 3, 1, 2}, false,
+		},
+		{
+			"within max buffered orders", txs, infos(0, 1, 2),
+			func(config *cfg.SidecarConfig) { config.MaxBufferedOrders = 2 },
+			nil, true,
+		},
+		{
+			"over max buffered orders", txs, infos(0, 1, 2),
+			func(config *cfg.SidecarConfig) { config.MaxBufferedOrders = 1 },
+			ErrBundleOverLimit{0, 1, "max_buffered_orders", 1, 2}, false,
+		},
+		{
+			"within soft max txs bytes", txs, infos(0, 1, 2),
+			func(config *cfg.SidecarConfig) { config.SoftMaxTxsBytes = 40 },
+			nil, true,
+		},
+		{
+			"orders from 1", txs, infos(1, 2, 3),
+			func(config *cfg.SidecarConfig) { config.BundleOrderBase = 1 },
+			nil, true,
+		},
+		{
+			"order 0 when orders are from 1", txs, infos(1, 0, 2),
+			func(config *cfg.SidecarConfig) { config.BundleOrderBase = 1 },
+			ErrTxMalformedForBundle{0, 3, 1, -1}, true,
+		},
+		{
+			"over soft max txs bytes", txs, infos(0, 1, 2),
+			func(config *cfg.SidecarConfig) { config.SoftMaxTxsBytes = 30 },
+			ErrBundleOverLimit{0, 1, "soft_max_txs_bytes", 30, 40}, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := cfg.TestSidecarConfig()
+			if tt.config != nil {
+				tt.config(config)
+			}
+			assert.Equal(t, tt.err, ValidateBundle(tt.txs, tt.infos, config))
+
+			if !tt.matchesAddTx {
+				return
+			}
+			sidecar := NewCListSidecar(config, 0)
+			var err error
+			for i, tx := range tt.txs {
+				if err = sidecar.AddTx(tx, tt.infos[i]); err != nil {
+					break
+				}
+			}
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestSidecarPeerBundleStats(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.MaxBufferedOrders = 1
+	sidecar := NewCListSidecar(config, 0)
+	assert.Empty(t, sidecar.PeerBundleStats())
+
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 0},
+		{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 1},
+	}, UnknownPeerID)
+	// peer 2 overflows the buffered orders limit, so its bundle is evicted
+	overflowInfo := testBundleInfo{BundleSize: 3, PeerId: 2, DesiredHeight: 1, BundleId: 2}
+	addTxToSidecar(t, sidecar, overflowInfo, 0)
+	addTxToSidecar(t, sidecar, overflowInfo, 1)
+	require.Equal(t, 2, sidecar.NumBundles())
+	// and sends a tx for a height that's already gone
+	assert.Error(t, sidecar.AddTx(types.Tx("stale"), TxInfo{SenderID: 2, DesiredHeight: 0, BundleSize: 1}))
+
+	// reaping twice only counts once
+	sidecar.ReapMaxTxs()
+	sidecar.ReapMaxTxs()
+
+	assert.Equal(t, map[uint16]PeerStats{
+		1: {AcceptedBundles: 2, ReapedBundles: 2},
+		2: {AcceptedBundles: 1, RejectedTxs: 1, EvictedBundles: 1},
+	}, sidecar.PeerBundleStats())
+}
+
+func TestSidecarBundleAdmissionHook(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	marker := types.Tx("marker")
+	var admitted []BundleMeta
+	sidecar.SetBundleAdmissionHook(func(txs []types.Tx, meta BundleMeta) error {
+		for _, tx := range txs {
+			if bytes.Equal(tx, marker) {
+				return errors.New("bundle contains the marker tx")
+			}
+		}
+		admitted = append(admitted, meta)
+		return nil
+	})
+
+	goodTxs := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 4, DesiredHeight: 1, BundleId: 0})
+
+	badInfo := TxInfo{SenderID: 5, DesiredHeight: 1, BundleId: 1, BundleSize: 2}
+	require.NoError(t, sidecar.AddTx(types.Tx("first"), badInfo))
+	badInfo.BundleOrder = 1
+	err := sidecar.AddTx(marker, badInfo)
+	var notAdmitted ErrBundleNotAdmitted
+	require.True(t, errors.As(err, &notAdmitted))
+	assert.Equal(t, SidecarCodeBundleNotAdmitted, SidecarErrorCode(err))
+
+	// only the admitted bundle is left, and reapable
+	assert.Equal(t, []BundleMeta{{DesiredHeight: 1, BundleId: 0, SenderID: 4}}, admitted)
+	assert.Equal(t, 1, sidecar.NumBundles())
+	assert.Equal(t, goodTxs, sidecar.ReapTxs(-1, -1))
+}
+
+func TestSidecarBundleAdmissionHookRunsOutsideLock(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	hookStarted, releaseHook := make(chan struct{}), make(chan struct{})
+	sidecar.SetBundleAdmissionHook(func(txs []types.Tx, meta BundleMeta) error {
+		close(hookStarted)
+		<-releaseHook
+		return nil
+	})
+
+	bInfo := testBundleInfo{BundleSize: 1, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		addTxToSidecar(t, sidecar, bInfo, 0)
+	}()
+	<-hookStarted
+
+	// the sidecar can still be updated and reaped while the hook runs, and
+	// the pending bundle isn't reaped
+	assert.Empty(t, sidecar.ReapMaxTxs())
+	sidecar.Lock()
+	sidecar.Unlock() // nolint:staticcheck
+
+	close(releaseHook)
+	<-done
+	assert.Len(t, sidecar.ReapMaxTxs(), 1)
+}
+
+func TestSidecarReapMaxBytesMaxGasTotals(t *testing.T) {
+	// the app wants 1 gas per tx
+	appConn, err := proxy.NewLocalClientCreator(&rejectingApp{}).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop() // nolint:errcheck
+
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0, WithSidecarProxyAppConn(appConn))
+	addBundlesToSidecar(t, sidecar, []testBundleInfo{
+		{BundleSize: 3, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 0},
+		{BundleSize: 4, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 1},
+		{BundleSize: 2, PeerId: UnknownPeerID, DesiredHeight: 1, BundleId: 2},
+	}, UnknownPeerID)
+
+	tests := []struct {
+		maxBytes, maxGas int64
+		expectedNumTxs   int
+	}{
+		{-1, -1, 9},
+		{-1, 5, 5},
+		{22 * 4, -1, 3},
+		{22 * 9, 2, 2},
+	}
+	for tcIndex, tt := range tests {
+		memTxs, totalBytes, totalGas := sidecar.ReapMaxBytesMaxGas(tt.maxBytes, tt.maxGas)
+		require.Len(t, memTxs, tt.expectedNumTxs, "tc #%d", tcIndex)
+
+		var expectedBytes, expectedGas int64
+		for _, memTx := range memTxs {
+			expectedBytes += types.ComputeProtoSizeForTxs([]types.Tx{memTx.tx})
+			expectedGas += memTx.gasWanted
+		}
+		assert.Equal(t, expectedBytes, totalBytes, "tc #%d", tcIndex)
+		assert.Equal(t, expectedGas, totalGas, "tc #%d", tcIndex)
+		assert.EqualValues(t, tt.expectedNumTxs, totalGas, "tc #%d", tcIndex)
+	}
+}
@@ -8,8 +8,76 @@ import (
 var (
 	// ErrTxInCache is returned to the client if we saw tx earlier
 	ErrTxInCache = errors.New("tx already exists in cache")
+
+	// ErrTxAlreadyInBundle is returned by the sidecar for an order it already
+	// holds, with the same tx. Like ErrTxInCache, it's not a failure.
+	ErrTxAlreadyInBundle = errors.New("tx already exists in bundle")
+
+	// ErrNoSidecarForBundleTx is returned by the mempool for a bundle tx when
+	// it has no sidecar to add it to
+	ErrNoSidecarForBundleTx = errors.New("bundle tx submitted, but there's no sidecar")
+
+	// ErrTxInBundle is returned by the mempool when asked to remove a tx the
+	// sidecar also holds in a bundle
+	ErrTxInBundle = errors.New("tx is part of a sidecar bundle")
+
+	// ErrNodeSyncing is returned by the sidecar for bundle txs sent while the
+	// node is still catching up, see SetSyncing
+	ErrNodeSyncing = errors.New("node is syncing, not accepting bundle txs")
 )
 
+// Codes for the errors returned by the sidecar, as reported by SidecarErrorCode.
+// These are stable, so they can be exposed over RPC: never reuse or renumber them.
+const (
+	SidecarCodeOK                     = 0
+	SidecarCodeUnknown                = 1
+	SidecarCodeTxInCache              = 2
+	SidecarCodeWrongHeight            = 3
+	SidecarCodeBundleFull             = 4
+	SidecarCodeTxMalformedForBundle   = 5
+	SidecarCodeNonMonotonicUpdate     = 6
+	SidecarCodeTxRejectedForBundle    = 7
+	SidecarCodeBundleNotAdmitted      = 8
+	SidecarCodeTxAlreadyInBundle      = 9
+	SidecarCodeBundleOverLimit        = 10
+	SidecarCodeBundleChecksBusy       = 11
+	SidecarCodeBundleExists           = 12
+	SidecarCodeBundleConflict         = 13
+	SidecarCodeSidecarIsFull          = 14
+	SidecarCodeResponseLengthMismatch = 15
+	SidecarCodeBundleOrderBeyondSize  = 16
+	SidecarCodeBundleHeightInPast     = 17
+	SidecarCodeMustIncludeBundle      = 18
+	SidecarCodeBundleSenderMismatch   = 19
+	SidecarCodeNodeSyncing            = 20
+	SidecarCodeForceComplete          = 21
+	SidecarCodeBundleNotReaped        = 22
+	SidecarCodeSidecarSettingFixed    = 23
+)
+
+// SidecarErrorCode maps an error returned by the sidecar to its code, so an
+// RPC handler can translate it consistently. A nil error maps to
+// SidecarCodeOK and errors the sidecar doesn't know about to SidecarCodeUnknown.
+func SidecarErrorCode(err error) int {
+	if err == nil {
+		return SidecarCodeOK
+	}
+	if errors.Is(err, ErrTxInCache) {
+		return SidecarCodeTxInCache
+	}
+	if errors.Is(err, ErrTxAlreadyInBundle) {
+		return SidecarCodeTxAlreadyInBundle
+	}
+	if errors.Is(err, ErrNodeSyncing) {
+		return SidecarCodeNodeSyncing
+	}
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+	return SidecarCodeUnknown
+}
+
 // ErrWrongHeight means the tx is asking to be in a height that doesn't match the current auction
 type ErrWrongHeight struct {
 	desiredHeight        int
@@ -20,6 +88,77 @@ func (e ErrWrongHeight) Error() string {
 	return fmt.Sprintf("Tx submitted for wrong height, asked for %d, but current auction height is %d", e.desiredHeight, e.currentAuctionHeight)
 }
 
+func (e ErrWrongHeight) Code() int { return SidecarCodeWrongHeight }
+
+// ErrBundleHeightInPast means the tx is asking to be in a height the sidecar
+// was already updated to, i.e. that's already committed
+type ErrBundleHeightInPast struct {
+	bundleId         int64
+	bundleHeight     int64
+	lastUpdateHeight int64
+}
+
+func (e ErrBundleHeightInPast) Error() string {
+	return fmt.Sprintf("Tx submitted for bundleId %d at height %d, but height %d is already committed", e.bundleId, e.bundleHeight, e.lastUpdateHeight)
+}
+
+func (e ErrBundleHeightInPast) Code() int { return SidecarCodeBundleHeightInPast }
+
+// ErrMustIncludeBundle means a reap couldn't include a bundle it was asked to
+// include ahead of the others, so it reaped nothing
+type ErrMustIncludeBundle struct {
+	bundleId     int64
+	bundleHeight int64
+	reason       string
+}
+
+func (e ErrMustIncludeBundle) Error() string {
+	return fmt.Sprintf("can't include bundleId %d at height %d in the reap: %s", e.bundleId, e.bundleHeight, e.reason)
+}
+
+func (e ErrMustIncludeBundle) Code() int { return SidecarCodeMustIncludeBundle }
+
+// ErrBundleSenderMismatch means the tx is an order of a bundle another peer
+// sent the first order of, which points to spoofing or a bug
+type ErrBundleSenderMismatch struct {
+	bundleId     int64
+	bundleHeight int64
+	sender       uint16
+	bundleSender uint16
+}
+
+func (e ErrBundleSenderMismatch) Error() string {
+	return fmt.Sprintf("Tx for bundleId %d at height %d sent by peer %d, but the bundle is from peer %d", e.bundleId, e.bundleHeight, e.sender, e.bundleSender)
+}
+
+func (e ErrBundleSenderMismatch) Code() int { return SidecarCodeBundleSenderMismatch }
+
+// ErrForceComplete means ForceComplete couldn't fill a bundle's missing
+// orders, so it left the bundle as it was
+type ErrForceComplete struct {
+	bundleId     int64
+	bundleHeight int64
+	reason       string
+}
+
+func (e ErrForceComplete) Error() string {
+	return fmt.Sprintf("can't force-complete bundleId %d at height %d: %s", e.bundleId, e.bundleHeight, e.reason)
+}
+
+func (e ErrForceComplete) Code() int { return SidecarCodeForceComplete }
+
+// ErrNonMonotonicUpdate means the sidecar was asked to update to a height lower than the last one it was updated to
+type ErrNonMonotonicUpdate struct {
+	height     int64
+	lastHeight int64
+}
+
+func (e ErrNonMonotonicUpdate) Error() string {
+	return fmt.Sprintf("Sidecar update to height %d is lower than last updated height %d", e.height, e.lastHeight)
+}
+
+func (e ErrNonMonotonicUpdate) Code() int { return SidecarCodeNonMonotonicUpdate }
+
 // ErrBundleFull means the tx is trying to enter a bundle that has already reached its limit
 type ErrBundleFull struct {
 	bundleId     int64
@@ -30,6 +169,8 @@ func (e ErrBundleFull) Error() string {
 	return fmt.Sprintf("Tx submitted but bundle is full, for bundleId %d with bundle size %d", e.bundleId, e.bundleHeight)
 }
 
+func (e ErrBundleFull) Code() int { return SidecarCodeBundleFull }
+
 // ErrTxMalformedForBundle is a general malformed error for specific cases
 type ErrTxMalformedForBundle struct {
 	bundleId     int64
@@ -42,6 +183,162 @@ func (e ErrTxMalformedForBundle) Error() string {
 	return fmt.Sprintf("Tx submitted but malformed with respect to bundling, for bundleId %d, at height %d, with bundleSize %d, and bundleOrder %d", e.bundleId, e.bundleHeight, e.bundleSize, e.bundleOrder)
 }
 
+func (e ErrTxMalformedForBundle) Code() int { return SidecarCodeTxMalformedForBundle }
+
+// ErrBundleOrderBeyondSize means the tx is for an order at or past the size
+// declared for its bundle, which never grows past that size
+type ErrBundleOrderBeyondSize struct {
+	bundleId     int64
+	bundleHeight int64
+	bundleSize   int64
+	bundleOrder  int64
+}
+
+func (e ErrBundleOrderBeyondSize) Error() string {
+	return fmt.Sprintf("bundleOrder %d is beyond the size %d of bundleId %d at height %d", e.bundleOrder, e.bundleSize, e.bundleId, e.bundleHeight)
+}
+
+func (e ErrBundleOrderBeyondSize) Code() int { return SidecarCodeBundleOrderBeyondSize }
+
+// ErrTxRejectedForBundle means the app rejected a tx in CheckTx, so its whole
+// bundle was dropped
+type ErrTxRejectedForBundle struct {
+	bundleId     int64
+	bundleHeight int64
+	code         uint32
+}
+
+func (e ErrTxRejectedForBundle) Error() string {
+	return fmt.Sprintf("Tx rejected by the app with code %d, dropping bundleId %d at height %d", e.code, e.bundleId, e.bundleHeight)
+}
+
+func (e ErrTxRejectedForBundle) Code() int { return SidecarCodeTxRejectedForBundle }
+
+// ErrBundleNotAdmitted means the bundle admission hook rejected the bundle, so
+// it was evicted
+type ErrBundleNotAdmitted struct {
+	bundleId     int64
+	bundleHeight int64
+	err          error
+}
+
+func (e ErrBundleNotAdmitted) Error() string {
+	return fmt.Sprintf("bundleId %d at height %d rejected by the admission hook: %v", e.bundleId, e.bundleHeight, e.err)
+}
+
+func (e ErrBundleNotAdmitted) Unwrap() error { return e.err }
+
+func (e ErrBundleNotAdmitted) Code() int { return SidecarCodeBundleNotAdmitted }
+
+// ErrBundleOverLimit means the bundle can never complete, as the sidecar would
+// evict it for going over one of its limits before its last tx arrives
+type ErrBundleOverLimit struct {
+	bundleId     int64
+	bundleHeight int64
+	limit        string
+	max          int64
+	actual       int64
+}
+
+func (e ErrBundleOverLimit) Error() string {
+	return fmt.Sprintf("bundleId %d at height %d is over the %s limit of %d while incomplete, with %d", e.bundleId, e.bundleHeight, e.limit, e.max, e.actual)
+}
+
+func (e ErrBundleOverLimit) Code() int { return SidecarCodeBundleOverLimit }
+
+// ErrBundleChecksBusy means the bundle was rejected because the sidecar was
+// already validating as many bundles as it's allowed to at once
+type ErrBundleChecksBusy struct {
+	bundleId     int64
+	bundleHeight int64
+	max          int
+}
+
+func (e ErrBundleChecksBusy) Error() string {
+	return fmt.Sprintf("bundleId %d at height %d rejected, already validating the maximum of %d bundles", e.bundleId, e.bundleHeight, e.max)
+}
+
+func (e ErrBundleChecksBusy) Code() int { return SidecarCodeBundleChecksBusy }
+
+// ErrBundleExists means a bundle couldn't be moved to a height, as there's
+// already a bundle with the same id there
+type ErrBundleExists struct {
+	bundleId     int64
+	bundleHeight int64
+}
+
+func (e ErrBundleExists) Error() string {
+	return fmt.Sprintf("there's already a bundle with bundleId %d at height %d", e.bundleId, e.bundleHeight)
+}
+
+func (e ErrBundleExists) Code() int { return SidecarCodeBundleExists }
+
+// ErrBundleConflict means a tx was sent for an order of a bundle that already
+// holds a different tx at that order, i.e. the same bundle id was reused at
+// the same height by the same sender for different content
+type ErrBundleConflict struct {
+	bundleId     int64
+	bundleHeight int64
+	bundleOrder  int64
+}
+
+func (e ErrBundleConflict) Error() string {
+	return fmt.Sprintf("bundleId %d at height %d already holds a different tx at bundleOrder %d", e.bundleId, e.bundleHeight, e.bundleOrder)
+}
+
+func (e ErrBundleConflict) Code() int { return SidecarCodeBundleConflict }
+
+// ErrSidecarIsFull means the sidecar already holds SidecarConfig.MaxSidecarTxs
+// txs
+type ErrSidecarIsFull struct {
+	numTxs int
+	maxTxs int
+}
+
+func (e ErrSidecarIsFull) Error() string {
+	return fmt.Sprintf("sidecar is full: number of txs %d (max: %d)", e.numTxs, e.maxTxs)
+}
+
+func (e ErrSidecarIsFull) Code() int { return SidecarCodeSidecarIsFull }
+
+// ErrResponseLengthMismatch means the sidecar was updated with a different
+// number of DeliverTx responses than committed txs
+type ErrResponseLengthMismatch struct {
+	numTxs       int
+	numResponses int
+}
+
+func (e ErrResponseLengthMismatch) Error() string {
+	return fmt.Sprintf("got %d DeliverTx responses for %d committed txs", e.numResponses, e.numTxs)
+}
+
+func (e ErrResponseLengthMismatch) Code() int { return SidecarCodeResponseLengthMismatch }
+
+// ErrBundleNotReaped means a proof or a requeue was requested for a bundle
+// that isn't part of the reaped set
+type ErrBundleNotReaped struct {
+	bundleId     int64
+	bundleHeight int64
+}
+
+func (e ErrBundleNotReaped) Error() string {
+	return fmt.Sprintf("bundleId %d at height %d is not part of the reaped bundles", e.bundleId, e.bundleHeight)
+}
+
+func (e ErrBundleNotReaped) Code() int { return SidecarCodeBundleNotReaped }
+
+// ErrSidecarSettingFixed means UpdateConfig was asked to change a sidecar
+// setting that only takes effect at startup
+type ErrSidecarSettingFixed struct {
+	Setting string
+}
+
+func (e ErrSidecarSettingFixed) Error() string {
+	return fmt.Sprintf("%s can't be changed at runtime", e.Setting)
+}
+
+func (e ErrSidecarSettingFixed) Code() int { return SidecarCodeSidecarSettingFixed }
+
 // ErrTxTooLarge means the tx is too big to be sent in a message to other peers
 type ErrTxTooLarge struct {
 	max    int
@@ -68,6 +365,17 @@ func (e ErrMempoolIsFull) Error() string {
 		e.txsBytes, e.maxTxsBytes)
 }
 
+// ErrReapTooLarge means reaped txs take more of a resource than a block allows
+type ErrReapTooLarge struct {
+	resource string // "bytes" or "gas"
+	max      int64
+	actual   int64
+}
+
+func (e ErrReapTooLarge) Error() string {
+	return fmt.Sprintf("reaped txs take %d %s, over the max of %d", e.actual, e.resource, e.max)
+}
+
 // ErrPreCheck is returned when tx is too big
 type ErrPreCheck struct {
 	Reason error
@@ -0,0 +1,49 @@
+package mempool
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSidecarErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, SidecarCodeOK},
+		{ErrTxInCache, SidecarCodeTxInCache},
+		{ErrTxAlreadyInBundle, SidecarCodeTxAlreadyInBundle},
+		{ErrWrongHeight{1, 2}, SidecarCodeWrongHeight},
+		{ErrBundleFull{0, 1}, SidecarCodeBundleFull},
+		{ErrTxMalformedForBundle{0, 1, 1, 2}, SidecarCodeTxMalformedForBundle},
+		{ErrNonMonotonicUpdate{1, 2}, SidecarCodeNonMonotonicUpdate},
+		{ErrTxRejectedForBundle{0, 1, 1}, SidecarCodeTxRejectedForBundle},
+		{ErrBundleNotAdmitted{0, 1, errors.New("simulation failed")}, SidecarCodeBundleNotAdmitted},
+		{ErrBundleOverLimit{0, 1, "max_buffered_orders", 2, 3}, SidecarCodeBundleOverLimit},
+		{ErrBundleChecksBusy{0, 1, 2}, SidecarCodeBundleChecksBusy},
+		{ErrBundleExists{0, 1}, SidecarCodeBundleExists},
+		{ErrBundleConflict{0, 1, 0}, SidecarCodeBundleConflict},
+		{ErrSidecarIsFull{1, 1}, SidecarCodeSidecarIsFull},
+		{ErrResponseLengthMismatch{2, 1}, SidecarCodeResponseLengthMismatch},
+		{ErrBundleOrderBeyondSize{0, 1, 2, 2}, SidecarCodeBundleOrderBeyondSize},
+		{ErrBundleHeightInPast{0, 1, 2}, SidecarCodeBundleHeightInPast},
+		{ErrMustIncludeBundle{0, 1, "no such bundle"}, SidecarCodeMustIncludeBundle},
+		{ErrBundleSenderMismatch{0, 1, 2, 1}, SidecarCodeBundleSenderMismatch},
+		{ErrNodeSyncing, SidecarCodeNodeSyncing},
+		{ErrForceComplete{0, 1, "no such bundle"}, SidecarCodeForceComplete},
+		{ErrBundleNotReaped{0, 1}, SidecarCodeBundleNotReaped},
+		{ErrSidecarSettingFixed{"max_sidecar_txs"}, SidecarCodeSidecarSettingFixed},
+		// wrapped errors keep their code
+		{fmt.Errorf("adding bundle: %w", ErrBundleFull{0, 1}), SidecarCodeBundleFull},
+		{fmt.Errorf("adding bundle: %w", ErrTxInCache), SidecarCodeTxInCache},
+		// errors the sidecar doesn't know about
+		{errors.New("some other error"), SidecarCodeUnknown},
+		{ErrTxTooLarge{1, 2}, SidecarCodeUnknown},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.code, SidecarErrorCode(tt.err), "error %v", tt.err)
+	}
+}
@@ -1,8 +1,12 @@
 package mempool
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/p2p"
@@ -55,6 +59,13 @@ type PriorityTxSidecar interface {
 
 	// TxsBytes returns the total size of all txs in the mempool.
 	TxsBytes() int64
+
+	// NumBundles returns the number of bundles in the sidecar, complete or not.
+	NumBundles() int
+
+	// HasTx returns whether the tx with txKey is held in one of the
+	// sidecar's bundles.
+	HasTx(txKey [TxKeySize]byte) bool
 }
 
 // Mempool defines the mempool interface.
@@ -156,6 +167,41 @@ type TxInfo struct {
 	BundleOrder int64
 	// total size of bundle
 	BundleSize int64
+	// last height the bundle may still be included in, set by the bundle's
+	// first order (0 - only DesiredHeight)
+	MaxHeight int64
+	// minimum number of orders, from the first, a partial reap takes of the
+	// bundle, set by the bundle's first order (0 - any), see
+	// SidecarConfig.AllowPartialBundles
+	MinOrdersToReap int64
+	// marks the tx as a must-include anchor of its bundle, see
+	// SidecarConfig.PinnedBundlePolicy
+	Pinned bool
+	// execution ordering hint for the tx, carried through to the reaped
+	// MempoolTx as is: bundles are still reaped in BundleOrder
+	ExecHint int64
+}
+
+// TxSource is where a MempoolTx was ingested from.
+type TxSource uint8
+
+const (
+	// SourceMempool is a tx that went through CheckTx into the mempool
+	SourceMempool TxSource = iota
+	// SourceSidecar is a tx reaped from a sidecar bundle
+	SourceSidecar
+)
+
+// String implements fmt.Stringer.
+func (s TxSource) String() string {
+	switch s {
+	case SourceMempool:
+		return "mempool"
+	case SourceSidecar:
+		return "sidecar"
+	default:
+		return fmt.Sprintf("TxSource(%d)", uint8(s))
+	}
 }
 
 // MempoolTx is a transaction that successfully ran
@@ -163,18 +209,23 @@ type MempoolTx struct {
 	height    int64    // height of state that this tx had been validated against
 	gasWanted int64    // amount of gas this tx states it will require
 	tx        types.Tx //
+	source    TxSource // where this tx came from
+	execHint  int64    // execution ordering hint, see TxInfo.ExecHint
 
 	// ids of peers who've sent us this tx (as a map for quick lookups).
 	// senders: PeerID -> bool
-	senders sync.Map
+	// a pointer, so a tx reaped from the sidecar shares its SidecarTx's senders
+	senders *sync.Map
 }
 
 // MempoolTx is a transaction that successfully ran
 type SidecarTx struct {
-	desiredHeight int64 // height that this tx wants to be included in
-	bundleId      int64 // ordered id of bundle
-	bundleOrder   int64 // order of tx within bundle
-	bundleSize    int64 // total size of bundle
+	desiredHeight int64  // height that this tx wants to be included in
+	bundleId      int64  // ordered id of bundle
+	bundleSender  uint16 // sender the bundle is namespaced under, see Key
+	bundleOrder   int64  // order of tx within bundle
+	bundleSize    int64  // total size of bundle
+	execHint      int64  // execution ordering hint, see TxInfo.ExecHint
 
 	gasWanted int64    // amount of gas this tx states it will require
 	tx        types.Tx // tx bytes
@@ -184,15 +235,142 @@ type SidecarTx struct {
 	senders sync.Map
 }
 
+// toMempoolTx returns scTx as a MempoolTx, as handed out by the sidecar reaps
+func (scTx *SidecarTx) toMempoolTx() *MempoolTx {
+	return &MempoolTx{
+		// CONTRACT: since the only height this could have been added into is desiredHeight = mem.height + 1, then this tx must have been validated against mem.height
+		height:    scTx.desiredHeight - 1,
+		gasWanted: scTx.gasWanted,
+		tx:        scTx.tx,
+		source:    SourceSidecar,
+		execHint:  scTx.execHint,
+		senders:   &scTx.senders,
+	}
+}
+
+// BundleMeta describes a bundle passed to a BundleAdmissionHook or a
+// BundleEvictionHook.
+type BundleMeta struct {
+	DesiredHeight int64
+	BundleId      int64
+	SenderID      uint16 // peer that sent the first order of the bundle
+}
+
+// BundleAdmissionHook validates a complete bundle, given its txs in bundle
+// order. Returning an error evicts the bundle from the sidecar.
+type BundleAdmissionHook func(txs []types.Tx, meta BundleMeta) error
+
+// BundleEvictionHook is notified of a bundle dropped from the sidecar before
+// its height was committed.
+type BundleEvictionHook func(meta BundleMeta)
+
+// PeerStats counts what happened to the bundles a peer sent the sidecar.
+// Bundles are attributed to the peer that sent their first order.
+type PeerStats struct {
+	AcceptedBundles     int64 // bundles started by the peer
+	RejectedTxs         int64 // txs from the peer AddTx returned an error for, other than ErrTxInCache or ErrTxAlreadyInBundle
+	ReapedBundles       int64 // bundles reaped for a proposal
+	EvictedBundles      int64 // bundles dropped before their height was committed
+	StrictEvictions     int64 // bundles evicted for malformed orders from the peer, see SidecarConfig.StrictMode
+	ABCIRejectedBundles int64 // bundles dropped because the app rejected a tx from the peer in CheckTx
+}
+
+// CombinedStats is a snapshot of the sizes of a mempool and its sidecar, see
+// CListMempool.CombinedStats.
+type CombinedStats struct {
+	MempoolTxs     int   // txs in the mempool
+	MempoolBytes   int64 // total size of the mempool's txs
+	SidecarTxs     int   // txs in the sidecar
+	SidecarBytes   int64 // total size of the sidecar's txs
+	SidecarBundles int   // bundles in the sidecar, complete or not
+}
+
 // Bundle stores information about a sidecar bundle
 type Bundle struct {
-	desiredHeight int64 // height that this bundle wants to be included in
-	bundleId      int64 // ordered id of bundle
-	currSize      int64 // total size of bundle
-	enforcedSize  int64 // total size of bundle
+	desiredHeight int64  // height that this bundle wants to be included in
+	maxHeight     int64  // last height that this bundle may be included in
+	bundleId      int64  // ordered id of bundle
+	sender        uint16 // sender the bundle is namespaced under, see Key
+	currSize      int64  // total size of bundle
+	enforcedSize  int64  // total size of bundle
+	// orders a partial reap must take of the bundle, see TxInfo.MinOrdersToReap
+	minOrdersToReap int64
 
 	gasWanted     int64     // amount of gas this tx states it will require
 	orderedTxsMap *sync.Map // map from bundleOrder to *mempoolTx
+	senderID      uint16    // peer that sent the first order of the bundle
+
+	reaped       int32 // set to 1 once the bundle was reaped for a proposal (atomic)
+	lastProgress int64 // sequence number of the last order added to the bundle (atomic)
+
+	pendingAdmission int32 // set to 1 until the admission hook accepts the bundle (atomic)
+	pinned           int32 // set to 1 once an order marked Pinned is added (atomic)
+
+	budgetSkips int32 // reaps in a row the bundle didn't fit in, see SidecarConfig.StarvedBundleThreshold (atomic)
+	prioritized int32 // set to 1 once the bundle starved, under SidecarStarvedBundlePrioritize (atomic)
+
+	// if set, the bundle's height was sealed with only its final order missing,
+	// which is still accepted until then (see SidecarConfig.LateOrderGrace)
+	lateDeadline time.Time
+}
+
+func (bundle *Bundle) key() Key {
+	return Key{bundle.desiredHeight, bundle.bundleId, bundle.sender}
+}
+
+// isComplete reports whether every order of the bundle has arrived.
+func (bundle *Bundle) isComplete() bool {
+	return atomic.LoadInt64(&bundle.currSize) >= bundle.enforcedSize
+}
+
+// DeriveBundleID derives a bundle id from the bundle's sender and the keys of
+// its txs, in bundle order. Searchers can use it to pick ids that won't
+// collide with other searchers' bundles.
+func DeriveBundleID(senderID uint16, txKeys [][TxKeySize]byte) int64 {
+	hasher := sha256.New()
+	var senderBytes [2]byte
+	binary.BigEndian.PutUint16(senderBytes[:], senderID)
+	hasher.Write(senderBytes[:])
+	for _, txKey := range txKeys {
+		hasher.Write(txKey[:])
+	}
+	// bundle ids are non-negative
+	return int64(binary.BigEndian.Uint64(hasher.Sum(nil)) >> 1)
+}
+
+// BundleReceipt reports what the sidecar accepted of a bundle submitted
+// through AddBundle or ImportBundles
+type BundleReceipt struct {
+	DesiredHeight int64 // height the bundle was submitted for
+	BundleId      int64 // id of the bundle
+	NumTxs        int   // number of txs accepted
+
+	TotalBytes int64 // total size of the accepted txs, in bytes
+	TotalGas   int64 // total gas wanted by the accepted txs
+
+	// why the bundle was rejected, only set by ImportBundles
+	Err error
+}
+
+// Accepted returns true if the whole bundle was accepted.
+func (r BundleReceipt) Accepted() bool {
+	return r.Err == nil
+}
+
+// BundleImport is a bundle to add through ImportBundles, see AddBundle for
+// how TxInfo applies to its txs
+type BundleImport struct {
+	Txs    types.Txs
+	TxInfo TxInfo
+}
+
+// IncompleteBundleInfo describes a bundle the sidecar holds that is still
+// missing some of its orders
+type IncompleteBundleInfo struct {
+	DesiredHeight int64   // height the bundle wants to be included in
+	BundleId      int64   // id of the bundle
+	BundleSize    int64   // number of orders the bundle expects
+	MissingOrders []int64 // orders not received yet, in ascending order
 }
 
 //--------------------------------------------------------------------------------
@@ -0,0 +1,38 @@
+// Package mempooltest provides fixtures for tests that exercise the mempool
+// and the sidecar from outside the mempool package.
+package mempooltest
+
+import (
+	mrand "math/rand"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// TestTxBytes is the size of every tx produced by GenerateTestBundle, matching
+// the 20 byte txs the mempool tests build inline.
+const TestTxBytes = 20
+
+// TestBundleInfo describes the bundle a set of generated txs belongs to
+type TestBundleInfo struct {
+	BundleSize    int64
+	DesiredHeight int64
+	BundleId      int64
+	PeerId        uint16
+}
+
+// GenerateTestBundle deterministically builds BundleSize txs for the given
+// bundle. The same seed and bundle info always yield the same txs, while
+// bundles with a different id or desired height get different txs under the
+// same seed, so they don't collide in the sidecar's cache.
+func GenerateTestBundle(seed int64, bundleInfo TestBundleInfo) types.Txs {
+	// mix the bundle identity into the seed so one seed can back a whole fixture
+	r := mrand.New(mrand.NewSource(seed ^ (bundleInfo.DesiredHeight << 32) ^ bundleInfo.BundleId)) // nolint:gosec // G404: Use of weak random number generator
+
+	txs := make(types.Txs, bundleInfo.BundleSize)
+	for i := range txs {
+		txBytes := make([]byte, TestTxBytes)
+		_, _ = r.Read(txBytes)
+		txs[i] = txBytes
+	}
+	return txs
+}
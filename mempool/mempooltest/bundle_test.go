@@ -0,0 +1,25 @@
+package mempooltest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTestBundleIsDeterministic(t *testing.T) {
+	bInfo := TestBundleInfo{BundleSize: 5, DesiredHeight: 1, BundleId: 0}
+
+	first := GenerateTestBundle(42, bInfo)
+	second := GenerateTestBundle(42, bInfo)
+	require.Len(t, first, 5)
+	assert.Equal(t, first, second, "same seed should yield identical bundles")
+	for _, tx := range first {
+		assert.Len(t, tx, TestTxBytes)
+	}
+
+	// a different seed, bundle id or height gives different txs
+	assert.NotEqual(t, first, GenerateTestBundle(43, bInfo))
+	assert.NotEqual(t, first, GenerateTestBundle(42, TestBundleInfo{BundleSize: 5, DesiredHeight: 1, BundleId: 1}))
+	assert.NotEqual(t, first, GenerateTestBundle(42, TestBundleInfo{BundleSize: 5, DesiredHeight: 2, BundleId: 0}))
+}
@@ -13,6 +13,11 @@ const (
 	MetricsSubsystem = "mempool"
 )
 
+// SidecarMetricsLabel is the label sidecar metrics are partitioned by, with
+// the value of the sidecar's SidecarConfig.MetricsLabel, so several sidecars
+// can report through the same Metrics.
+const SidecarMetricsLabel = "sidecar"
+
 // Metrics contains metrics exposed by this package.
 // see MetricsProvider for descriptions.
 type Metrics struct {
@@ -24,6 +29,23 @@ type Metrics struct {
 	FailedTxs metrics.Counter
 	// Number of times transactions are rechecked in the mempool.
 	RecheckTimes metrics.Counter
+	// Number of sidecar bundles dropped because the app rejected one of their txs.
+	RejectedSidecarBundles metrics.Counter
+	// Number of incomplete sidecar bundles evicted because the sidecar's txs
+	// went over its soft byte limit.
+	SoftLimitEvictedSidecarBundles metrics.Counter
+	// Number of incomplete sidecar bundles with pinned txs evicted to get back
+	// under the sidecar's limits.
+	PinnedEvictedSidecarBundles metrics.Counter
+	// Number of sidecar txs rejected for conflicting with the bundle already
+	// stored under their bundle id, height and sender.
+	ConflictingSidecarBundleTxs metrics.Counter
+	// Histogram of the time from the sidecar's auction firing for a height to
+	// that height being committed, in seconds.
+	SidecarAuctionToCommitSeconds metrics.Histogram
+	// Number of sidecar bundles found starving, after being skipped for not
+	// fitting in a reap's budget too many reaps in a row.
+	StarvedSidecarBundles metrics.Counter
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -34,6 +56,7 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 	for i := 0; i < len(labelsAndValues); i += 2 {
 		labels = append(labels, labelsAndValues[i])
 	}
+	sidecarLabels := append(append([]string{}, labels...), SidecarMetricsLabel)
 	return &Metrics{
 		Size: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
 			Namespace: namespace,
@@ -60,15 +83,72 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "recheck_times",
 			Help:      "Number of times transactions are rechecked in the mempool.",
 		}, labels).With(labelsAndValues...),
+		RejectedSidecarBundles: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "rejected_sidecar_bundles",
+			Help:      "Number of sidecar bundles dropped because the app rejected one of their txs.",
+		}, sidecarLabels).With(labelsAndValues...),
+		SoftLimitEvictedSidecarBundles: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "soft_limit_evicted_sidecar_bundles",
+			Help:      "Number of incomplete sidecar bundles evicted because the sidecar's txs went over its soft byte limit.",
+		}, sidecarLabels).With(labelsAndValues...),
+		PinnedEvictedSidecarBundles: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pinned_evicted_sidecar_bundles",
+			Help:      "Number of incomplete sidecar bundles with pinned txs evicted to get back under the sidecar's limits.",
+		}, sidecarLabels).With(labelsAndValues...),
+		ConflictingSidecarBundleTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "conflicting_sidecar_bundle_txs",
+			Help:      "Number of sidecar txs rejected for conflicting with the bundle already stored under their bundle id, height and sender.",
+		}, sidecarLabels).With(labelsAndValues...),
+		SidecarAuctionToCommitSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "sidecar_auction_to_commit_seconds",
+			Help:      "Time from the sidecar's auction firing for a height to that height being committed, in seconds.",
+			Buckets:   stdprometheus.ExponentialBuckets(0.05, 2, 10),
+		}, sidecarLabels).With(labelsAndValues...),
+		StarvedSidecarBundles: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "starved_sidecar_bundles",
+			Help:      "Number of sidecar bundles found starving, after being skipped for not fitting in a reap's budget too many reaps in a row.",
+		}, sidecarLabels).With(labelsAndValues...),
 	}
 }
 
 // NopMetrics returns no-op Metrics.
 func NopMetrics() *Metrics {
 	return &Metrics{
-		Size:         discard.NewGauge(),
-		TxSizeBytes:  discard.NewHistogram(),
-		FailedTxs:    discard.NewCounter(),
-		RecheckTimes: discard.NewCounter(),
+		Size:                   discard.NewGauge(),
+		TxSizeBytes:            discard.NewHistogram(),
+		FailedTxs:              discard.NewCounter(),
+		RecheckTimes:           discard.NewCounter(),
+		RejectedSidecarBundles: discard.NewCounter(),
+
+		SoftLimitEvictedSidecarBundles: discard.NewCounter(),
+		PinnedEvictedSidecarBundles:    discard.NewCounter(),
+		ConflictingSidecarBundleTxs:    discard.NewCounter(),
+		SidecarAuctionToCommitSeconds:  discard.NewHistogram(),
+		StarvedSidecarBundles:          discard.NewCounter(),
 	}
 }
+
+// withSidecarLabel returns a copy of m with the sidecar metrics labeled with
+// label, see SidecarMetricsLabel.
+func (m *Metrics) withSidecarLabel(label string) *Metrics {
+	labeled := *m
+	labeled.RejectedSidecarBundles = m.RejectedSidecarBundles.With(SidecarMetricsLabel, label)
+	labeled.SoftLimitEvictedSidecarBundles = m.SoftLimitEvictedSidecarBundles.With(SidecarMetricsLabel, label)
+	labeled.PinnedEvictedSidecarBundles = m.PinnedEvictedSidecarBundles.With(SidecarMetricsLabel, label)
+	labeled.ConflictingSidecarBundleTxs = m.ConflictingSidecarBundleTxs.With(SidecarMetricsLabel, label)
+	labeled.SidecarAuctionToCommitSeconds = m.SidecarAuctionToCommitSeconds.With(SidecarMetricsLabel, label)
+	labeled.StarvedSidecarBundles = m.StarvedSidecarBundles.With(SidecarMetricsLabel, label)
+	return &labeled
+}
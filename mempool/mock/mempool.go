@@ -89,3 +89,6 @@ func (PriorityTxSidecar) TargetValidator() []byte { return []byte{} }
 
 func (PriorityTxSidecar) Size() int       { return 0 }
 func (PriorityTxSidecar) TxsBytes() int64 { return 0 }
+func (PriorityTxSidecar) NumBundles() int { return 0 }
+
+func (PriorityTxSidecar) HasTx(_ [mempl.TxKeySize]byte) bool { return false }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sync/atomic"
 	"time"
 
 	cfg "github.com/tendermint/tendermint/config"
@@ -38,8 +39,25 @@ type Reactor struct {
 	mempool *CListMempool
 	sidecar *CListPriorityTxSidecar
 	ids     *mempoolIDs
+
+	// peers allowed to submit bundles over the SidecarChannel, if non empty
+	sidecarAllowlistMtx tmsync.RWMutex
+	sidecarAllowlist    map[p2p.ID]struct{}
+	numDroppedSidecarTx int64 // atomic, txs dropped for coming from a peer not in the allowlist
+
+	// notified of the acks of bundles sent to peers, see SetBundleAckHook
+	bundleAckHookMtx tmsync.RWMutex
+	bundleAckHook    BundleAckHook
+
+	// peers a SyncBundles replay is running for, see replaySidecarBundles
+	replayingMtx tmsync.Mutex
+	replaying    map[p2p.ID]struct{}
 }
 
+// BundleAckHook is notified of a peer acknowledging the bundle with bundleID
+// at desiredHeight, see Reactor.SetBundleAckHook.
+type BundleAckHook func(peerID p2p.ID, desiredHeight, bundleID int64)
+
 type mempoolIDs struct {
 	mtx       tmsync.RWMutex
 	peerMap   map[p2p.ID]uint16
@@ -110,11 +128,58 @@ func NewReactor(config *cfg.MempoolConfig, mempool *CListMempool, sidecar *CList
 		mempool: mempool,
 		sidecar: sidecar,
 		ids:     newMempoolIDs(),
+
+		replaying: make(map[p2p.ID]struct{}),
 	}
 	memR.BaseReactor = *p2p.NewBaseReactor("Mempool", memR)
 	return memR
 }
 
+// SetSidecarPeerAllowlist restricts the peers that bundles are accepted from
+// to the given ones. An empty list accepts bundles from any sidecar peer.
+// It can be called again at any time to reload the allowlist.
+func (memR *Reactor) SetSidecarPeerAllowlist(peerIDs []p2p.ID) {
+	allowlist := make(map[p2p.ID]struct{}, len(peerIDs))
+	for _, id := range peerIDs {
+		allowlist[id] = struct{}{}
+	}
+
+	memR.sidecarAllowlistMtx.Lock()
+	defer memR.sidecarAllowlistMtx.Unlock()
+	memR.sidecarAllowlist = allowlist
+}
+
+// isSidecarPeerAllowed returns true if bundles from the given peer can be
+// added to the sidecar.
+func (memR *Reactor) isSidecarPeerAllowed(peerID p2p.ID) bool {
+	memR.sidecarAllowlistMtx.RLock()
+	defer memR.sidecarAllowlistMtx.RUnlock()
+
+	if len(memR.sidecarAllowlist) == 0 {
+		return true
+	}
+	_, ok := memR.sidecarAllowlist[peerID]
+	return ok
+}
+
+// SetBundleAckHook sets a hook notified of every bundle a sidecar peer
+// acknowledges, once it holds all of the bundle's orders, so the sender can
+// confirm bundles it forwarded propagated. Acks are only sent, and received
+// ones only passed on, when SidecarConfig.AckBundles is set. The hook runs on
+// the peer's receive routine, so it must be quick. A nil hook is never
+// called.
+func (memR *Reactor) SetBundleAckHook(hook BundleAckHook) {
+	memR.bundleAckHookMtx.Lock()
+	defer memR.bundleAckHookMtx.Unlock()
+	memR.bundleAckHook = hook
+}
+
+// NumDroppedSidecarTxs returns the number of sidecar txs dropped for being
+// received from a peer not in the allowlist.
+func (memR *Reactor) NumDroppedSidecarTxs() int64 {
+	return atomic.LoadInt64(&memR.numDroppedSidecarTx)
+}
+
 // InitPeer implements Reactor by creating a state for the peer.
 func (memR *Reactor) InitPeer(peer p2p.Peer) p2p.Peer {
 	memR.ids.ReserveForPeer(peer)
@@ -171,10 +236,18 @@ func (memR *Reactor) AddPeer(peer p2p.Peer) {
 			go memR.broadcastSidecarTxRoutine(peer)
 		}
 	}
+	// catch up on the bundles the peer holds for the auctions still ahead
+	if peer.IsSidecarPeer() && !memR.RequestSyncBundles(peer, memR.sidecar.HeightForFiringAuction()) {
+		memR.Logger.Error("Could not request bundles from sidecar peer", "peer", peer)
+	}
 }
 
 // RemovePeer implements Reactor.
 func (memR *Reactor) RemovePeer(peer p2p.Peer, reason interface{}) {
+	// before its id is reclaimed and possibly handed to another peer
+	if peerID := memR.ids.GetForPeer(peer); peerID != UnknownPeerID {
+		memR.sidecar.OnPeerDisconnect(peerID)
+	}
 	memR.ids.Reclaim(peer)
 	// broadcast routine checks if peer is gone and returns
 }
@@ -195,42 +268,186 @@ func (memR *Reactor) Receive(chID byte, src p2p.Peer, msgBytes []byte) {
 		if src != nil {
 			txInfo.SenderP2PID = src.ID()
 		}
+		// bundle txs get the same checks as over the SidecarChannel
+		if msg.BundleSize > 0 && (!isSidecarPeer || !memR.isSidecarPeerAllowed(txInfo.SenderP2PID)) {
+			memR.Logger.Info("Dropping bundle txs from peer that's not an allowed sidecar peer", "src", src, "numTxs", len(msg.Txs))
+			atomic.AddInt64(&memR.numDroppedSidecarTx, int64(len(msg.Txs)))
+			return
+		}
+		txInfo.DesiredHeight = msg.DesiredHeight
+		txInfo.BundleId = msg.BundleId
+		txInfo.BundleOrder = msg.BundleOrder
+		txInfo.BundleSize = msg.BundleSize
 		for _, tx := range msg.Txs {
 
 			err = memR.mempool.CheckTx(tx, nil, txInfo)
 			if err == ErrTxInCache {
 				memR.Logger.Debug("Tx already exists in cache", "tx", txID(tx))
+			} else if err == ErrTxAlreadyInBundle {
+				memR.Logger.Debug("Tx already exists in bundle", "tx", txID(tx))
 			} else if err != nil {
 				memR.Logger.Info("Could not check tx", "tx", txID(tx), "err", err)
 			}
 		}
 	} else if chID == SidecarChannel && isSidecarPeer {
-		msg, err := memR.decodeBundleMsg(msgBytes)
+		decoded, err := memR.decodeBundleMsg(msgBytes)
 		if err != nil {
 			memR.Logger.Error("Error decoding message", "src", src, "chId", chID, "err", err)
 			memR.Switch.StopPeerForError(src, err)
 			return
 		}
-		fmt.Println("[mev-tendermint] Reactor (receive) RECEIVED TX FROM ", src.ID())
-		// memR.Logger.Debug("Receive Sidecar Tx", "src", src, "chId", chID, "msg", msg)
-		txInfo := TxInfo{SenderID: memR.ids.GetForPeer(src), DesiredHeight: msg.DesiredHeight, BundleId: msg.BundleId, BundleOrder: msg.BundleOrder, BundleSize: msg.BundleSize}
-		if src != nil {
-			txInfo.SenderP2PID = src.ID()
-		}
-		for _, tx := range msg.Txs {
-			fmt.Println(fmt.Sprintf("[mev-tendermint] Reactor (receive): received sidecar tx %.20q! desiredHeight %d, bundleId %d, bundleOrder %d, bundleSize %d", tx, msg.DesiredHeight, msg.BundleId, msg.BundleOrder, msg.BundleSize))
-
-			err = memR.sidecar.AddTx(tx, txInfo)
-			if err == ErrTxInCache {
-				memR.Logger.Debug("SidecarTx already exists in cache", "tx", txID(tx))
-			} else if err != nil {
-				memR.Logger.Info("Could not add SidecarTx", "tx", txID(tx), "err", err)
+		switch msg := decoded.(type) {
+		case SyncBundlesMessage:
+			memR.Logger.Debug("Received SyncBundles request", "src", src, "fromHeight", msg.FromHeight)
+			// one replay at a time per peer, each one resends the whole sidecar
+			if !memR.startReplay(src.ID()) {
+				memR.Logger.Debug("Ignoring SyncBundles request, already replaying to peer", "src", src)
+				return
 			}
+			go func() {
+				defer memR.finishReplay(src.ID())
+				memR.replaySidecarBundles(src, msg.FromHeight)
+			}()
+		case AckBundleMessage:
+			memR.receiveBundleAck(src, msg)
+		case MEVTxsMessage:
+			memR.receiveSidecarTxs(src, msg)
 		}
 	}
 	// broadcasting happens from go routines per peer
 }
 
+// receiveSidecarTxs adds txs received over the SidecarChannel to the sidecar.
+func (memR *Reactor) receiveSidecarTxs(src p2p.Peer, msg MEVTxsMessage) {
+	fmt.Println("[mev-tendermint] Reactor (receive) RECEIVED TX FROM ", src.ID())
+	if !memR.isSidecarPeerAllowed(src.ID()) {
+		memR.Logger.Info("Dropping SidecarTxs from peer not in the allowlist", "src", src, "numTxs", len(msg.Txs))
+		atomic.AddInt64(&memR.numDroppedSidecarTx, int64(len(msg.Txs)))
+		return
+	}
+	// memR.Logger.Debug("Receive Sidecar Tx", "src", src, "chId", chID, "msg", msg)
+	txInfo := TxInfo{SenderID: memR.ids.GetForPeer(src), DesiredHeight: msg.DesiredHeight, BundleId: msg.BundleId, BundleOrder: msg.BundleOrder, BundleSize: msg.BundleSize}
+	if src != nil {
+		txInfo.SenderP2PID = src.ID()
+	}
+	for _, tx := range msg.Txs {
+		fmt.Println(fmt.Sprintf("[mev-tendermint] Reactor (receive): received sidecar tx %.20q! desiredHeight %d, bundleId %d, bundleOrder %d, bundleSize %d", tx, msg.DesiredHeight, msg.BundleId, msg.BundleOrder, msg.BundleSize))
+
+		completed, err := memR.sidecar.addTxCompleted(tx, txInfo)
+		// acked once, for the order that completed the peer's bundle
+		if completed && memR.sidecar.currentConfig().AckBundles {
+			memR.sendBundleAck(src, msg.DesiredHeight, msg.BundleId)
+		}
+		if err == ErrTxInCache {
+			memR.Logger.Debug("SidecarTx already exists in cache", "tx", txID(tx))
+		} else if err == ErrTxAlreadyInBundle {
+			memR.Logger.Debug("SidecarTx already exists in bundle", "tx", txID(tx))
+		} else if err != nil {
+			memR.Logger.Info("Could not add SidecarTx", "tx", txID(tx), "err", err)
+		}
+	}
+}
+
+// sendBundleAck acknowledges to peer that the sidecar holds every order of
+// the bundle with bundleID at desiredHeight, see SidecarConfig.AckBundles.
+// The ack is dropped if it can't be queued right away.
+func (memR *Reactor) sendBundleAck(peer p2p.Peer, desiredHeight, bundleID int64) {
+	msg := protomem.MEVMessage{
+		Sum: &protomem.MEVMessage_AckBundle{
+			AckBundle: &protomem.AckBundle{DesiredHeight: desiredHeight, BundleId: bundleID},
+		},
+	}
+	bz, err := msg.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	if !peer.TrySend(SidecarChannel, bz) {
+		memR.Logger.Info("Could not send AckBundle", "peer", peer, "desiredHeight", desiredHeight, "bundleId", bundleID)
+	}
+}
+
+// receiveBundleAck passes an ack from src on to the bundle ack hook, if acks
+// are enabled.
+func (memR *Reactor) receiveBundleAck(src p2p.Peer, msg AckBundleMessage) {
+	if !memR.sidecar.currentConfig().AckBundles {
+		memR.Logger.Debug("Ignoring AckBundle, bundle acks are disabled", "src", src)
+		return
+	}
+	memR.Logger.Debug("Received AckBundle", "src", src, "desiredHeight", msg.DesiredHeight, "bundleId", msg.BundleId)
+
+	memR.bundleAckHookMtx.RLock()
+	hook := memR.bundleAckHook
+	memR.bundleAckHookMtx.RUnlock()
+	if hook != nil {
+		hook(src.ID(), msg.DesiredHeight, msg.BundleId)
+	}
+}
+
+// RequestSyncBundles asks peer to replay every sidecar bundle it currently
+// holds with a desired height at or above fromHeight, e.g. to catch up on
+// bundles gossiped while the two were disconnected. Replayed txs go through
+// the usual SidecarChannel checks, so duplicates are dropped by the cache.
+// Returns false if the request could not be queued.
+func (memR *Reactor) RequestSyncBundles(peer p2p.Peer, fromHeight int64) bool {
+	if !peer.IsSidecarPeer() {
+		return false
+	}
+	msg := protomem.MEVMessage{
+		Sum: &protomem.MEVMessage_SyncBundles{
+			SyncBundles: &protomem.SyncBundles{FromHeight: fromHeight},
+		},
+	}
+	bz, err := msg.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	return peer.Send(SidecarChannel, bz)
+}
+
+// startReplay marks a SyncBundles replay as running for peerID, returning
+// false if one already is.
+func (memR *Reactor) startReplay(peerID p2p.ID) bool {
+	memR.replayingMtx.Lock()
+	defer memR.replayingMtx.Unlock()
+	if _, ok := memR.replaying[peerID]; ok {
+		return false
+	}
+	memR.replaying[peerID] = struct{}{}
+	return true
+}
+
+// finishReplay marks the SyncBundles replay for peerID as done.
+func (memR *Reactor) finishReplay(peerID p2p.ID) {
+	memR.replayingMtx.Lock()
+	defer memR.replayingMtx.Unlock()
+	delete(memR.replaying, peerID)
+}
+
+// replaySidecarBundles sends peer every sidecar tx with a desired height at
+// or above fromHeight, in sidecar insertion order.
+func (memR *Reactor) replaySidecarBundles(peer p2p.Peer, fromHeight int64) {
+	numSent := 0
+	for e := memR.sidecar.TxsFront(); e != nil; e = e.Next() {
+		if !memR.IsRunning() || !peer.IsRunning() {
+			return
+		}
+		scTx, ok := e.Value.(*SidecarTx)
+		if !ok || scTx.desiredHeight < fromHeight {
+			continue
+		}
+		bz, err := newSidecarTxMessage(scTx, int64(memR.sidecar.currentConfig().BundleOrderBase)).Marshal()
+		if err != nil {
+			panic(err)
+		}
+		if !peer.Send(SidecarChannel, bz) {
+			memR.Logger.Info("Could not replay SidecarTx to peer", "peer", peer, "tx", txID(scTx.tx))
+			continue
+		}
+		numSent++
+	}
+	memR.Logger.Debug("Replayed sidecar bundles", "peer", peer, "fromHeight", fromHeight, "numTxs", numSent)
+}
+
 // PeerState describes the state of a peer.
 type PeerState interface {
 	GetHeight() int64
@@ -269,16 +486,7 @@ func (memR *Reactor) broadcastSidecarTxRoutine(peer p2p.Peer) {
 		if scTx, okConv := next.Value.(*SidecarTx); okConv && isSidecarPeer {
 			fmt.Println("[mev-tendermint]: BroadcastSidecarTx() as sidecarTx to peer", peerID)
 			if _, ok := scTx.senders.Load(peerID); !ok {
-				msg := protomem.MEVMessage{
-					Sum: &protomem.MEVMessage_Txs{
-						Txs: &protomem.Txs{Txs: [][]byte{scTx.tx}},
-					},
-					DesiredHeight: scTx.desiredHeight,
-					BundleId:      scTx.bundleId,
-					BundleOrder:   scTx.bundleOrder,
-					BundleSize:    scTx.bundleSize,
-				}
-				bz, err := msg.Marshal()
+				bz, err := newSidecarTxMessage(scTx, int64(memR.sidecar.currentConfig().BundleOrderBase)).Marshal()
 				if err != nil {
 					panic(err)
 				}
@@ -383,11 +591,37 @@ func (memR *Reactor) broadcastMempoolTxRoutine(peer p2p.Peer) {
 //-----------------------------------------------------------------------------
 // Messages
 
-func (memR *Reactor) decodeBundleMsg(bz []byte) (MEVTxsMessage, error) {
+// newSidecarTxMessage wraps scTx and its bundle info for the SidecarChannel,
+// with its order counted from orderBase, see SidecarConfig.BundleOrderBase.
+func newSidecarTxMessage(scTx *SidecarTx, orderBase int64) *protomem.MEVMessage {
+	return &protomem.MEVMessage{
+		Sum: &protomem.MEVMessage_Txs{
+			Txs: &protomem.Txs{Txs: [][]byte{scTx.tx}},
+		},
+		DesiredHeight: scTx.desiredHeight,
+		BundleId:      scTx.bundleId,
+		BundleOrder:   scTx.bundleOrder + orderBase,
+		BundleSize:    scTx.bundleSize,
+	}
+}
+
+// decodeBundleMsg returns a MEVTxsMessage, a SyncBundlesMessage or an
+// AckBundleMessage.
+func (memR *Reactor) decodeBundleMsg(bz []byte) (interface{}, error) {
 	msg := protomem.MEVMessage{}
 	err := msg.Unmarshal(bz)
 	if err != nil {
-		return MEVTxsMessage{}, err
+		return nil, err
+	}
+
+	if i, ok := msg.Sum.(*protomem.MEVMessage_SyncBundles); ok {
+		return SyncBundlesMessage{FromHeight: i.SyncBundles.GetFromHeight()}, nil
+	}
+	if i, ok := msg.Sum.(*protomem.MEVMessage_AckBundle); ok {
+		return AckBundleMessage{
+			DesiredHeight: i.AckBundle.GetDesiredHeight(),
+			BundleId:      i.AckBundle.GetBundleId(),
+		}, nil
 	}
 
 	var message MEVTxsMessage
@@ -438,7 +672,11 @@ func (memR *Reactor) decodeMsg(bz []byte) (TxsMessage, error) {
 		}
 
 		message = TxsMessage{
-			Txs: decoded,
+			Txs:           decoded,
+			DesiredHeight: msg.DesiredHeight,
+			BundleId:      msg.BundleId,
+			BundleOrder:   msg.BundleOrder,
+			BundleSize:    msg.BundleSize,
 		}
 		return message, nil
 	}
@@ -447,9 +685,15 @@ func (memR *Reactor) decodeMsg(bz []byte) (TxsMessage, error) {
 
 //-------------------------------------
 
-// TxsMessage is a Message containing transactions.
+// TxsMessage is a Message containing transactions. If BundleSize is set, they
+// are orders of a sidecar bundle. The bundle fields are receive-only: they are
+// set by external submitters, and broadcastMempoolTxRoutine never sets them.
 type TxsMessage struct {
-	Txs []types.Tx
+	Txs           []types.Tx
+	DesiredHeight int64
+	BundleId      int64
+	BundleOrder   int64
+	BundleSize    int64
 }
 
 // TxsMessage is a Message containing transactions.
@@ -461,6 +705,19 @@ type MEVTxsMessage struct {
 	BundleSize    int64
 }
 
+// SyncBundlesMessage is a request to replay sidecar bundles at or above
+// FromHeight.
+type SyncBundlesMessage struct {
+	FromHeight int64
+}
+
+// AckBundleMessage acknowledges receipt of the sidecar bundle with BundleId
+// at DesiredHeight.
+type AckBundleMessage struct {
+	DesiredHeight int64
+	BundleId      int64
+}
+
 // String returns a string representation of the TxsMessage.
 func (m *TxsMessage) String() string {
 	return fmt.Sprintf("[TxsMessage %v]", m.Txs)
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -306,6 +307,326 @@ func TestBroadcastTxForPeerStopsWhenReactorStops(t *testing.T) {
 	leaktest.CheckTimeout(t, 10*time.Second)()
 }
 
+func TestReactorSidecarPeerAllowlist(t *testing.T) {
+	config := cfg.TestConfig()
+	reactors := makeAndConnectReactors(config, 1)
+	reactor := reactors[0]
+	defer func() {
+		if err := reactor.Stop(); err != nil {
+			assert.NoError(t, err)
+		}
+	}()
+
+	allowed, other := mock.NewPeer(nil), mock.NewPeer(nil)
+	reactor.InitPeer(allowed)
+	reactor.InitPeer(other)
+	reactor.SetSidecarPeerAllowlist([]p2p.ID{allowed.ID()})
+
+	bInfo := TxInfo{DesiredHeight: 1, BundleId: 0, BundleSize: 2}
+	reactor.Receive(SidecarChannel, other, sidecarMsgBytes(t, []byte{0x01}, bInfo))
+	assert.Equal(t, 0, reactor.sidecar.Size())
+	assert.EqualValues(t, 1, reactor.NumDroppedSidecarTxs())
+
+	reactor.Receive(SidecarChannel, allowed, sidecarMsgBytes(t, []byte{0x02}, bInfo))
+	assert.Equal(t, 1, reactor.sidecar.Size())
+
+	// reloading the allowlist takes effect right away
+	reactor.SetSidecarPeerAllowlist([]p2p.ID{other.ID()})
+	bInfo.BundleOrder = 1
+	reactor.Receive(SidecarChannel, allowed, sidecarMsgBytes(t, []byte{0x03}, bInfo))
+	assert.Equal(t, 1, reactor.sidecar.Size())
+	assert.EqualValues(t, 2, reactor.NumDroppedSidecarTxs())
+	// in a bundle of its own, as a bundle's orders all come from one peer
+	reactor.Receive(SidecarChannel, other, sidecarMsgBytes(t, []byte{0x01}, TxInfo{DesiredHeight: 1, BundleId: 2, BundleSize: 1}))
+	assert.Equal(t, 2, reactor.sidecar.Size())
+
+	// and an empty one accepts every sidecar peer
+	reactor.SetSidecarPeerAllowlist(nil)
+	bInfo = TxInfo{DesiredHeight: 1, BundleId: 1, BundleSize: 1}
+	reactor.Receive(SidecarChannel, allowed, sidecarMsgBytes(t, []byte{0x04}, bInfo))
+	assert.Equal(t, 3, reactor.sidecar.Size())
+	assert.EqualValues(t, 2, reactor.NumDroppedSidecarTxs())
+}
+
+func TestReactorBundleTxsOverMempoolChannel(t *testing.T) {
+	config := cfg.TestConfig()
+	appConn, err := proxy.NewLocalClientCreator(kvstore.NewApplication()).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop() // nolint:errcheck
+	sidecar := NewCListSidecar(config.Sidecar, 0)
+	mempool := NewCListMempool(config.Mempool, appConn, 0, WithSidecar(sidecar))
+	reactor := NewReactor(config.Mempool, mempool, sidecar)
+	reactor.SetLogger(mempoolLogger())
+
+	peer := mock.NewPeer(nil)
+	reactor.InitPeer(peer)
+
+	// bundle txs are routed to the sidecar
+	bInfo := TxInfo{DesiredHeight: 1, BundleId: 0, BundleSize: 2}
+	reactor.Receive(MempoolChannel, peer, bundleMempoolMsgBytes(t, []byte{0x01}, bInfo))
+	bInfo.BundleOrder = 1
+	reactor.Receive(MempoolChannel, peer, bundleMempoolMsgBytes(t, []byte{0x02}, bInfo))
+	assert.Equal(t, 2, sidecar.Size())
+	assert.Len(t, sidecar.ReapMaxTxs(), 2)
+	assert.Zero(t, mempool.Size())
+
+	// while other txs still go to the mempool
+	reactor.Receive(MempoolChannel, peer, bundleMempoolMsgBytes(t, []byte{0x03}, TxInfo{}))
+	assert.Equal(t, 1, mempool.Size())
+	assert.Equal(t, 2, sidecar.Size())
+
+	// bundle txs from peers that aren't sidecar peers are dropped
+	other := mock.NewPeer(nil)
+	other.SidecarPeer = false
+	reactor.InitPeer(other)
+	bInfo = TxInfo{DesiredHeight: 1, BundleId: 1, BundleSize: 1}
+	reactor.Receive(MempoolChannel, other, bundleMempoolMsgBytes(t, []byte{0x04}, bInfo))
+	assert.Equal(t, 2, sidecar.Size())
+	assert.EqualValues(t, 1, reactor.NumDroppedSidecarTxs())
+
+	// and without a sidecar, the mempool rejects them
+	mempool = NewCListMempool(config.Mempool, appConn, 0)
+	assert.Equal(t, ErrNoSidecarForBundleTx, mempool.CheckTx([]byte{0x04}, nil, bInfo))
+	assert.Zero(t, mempool.Size())
+}
+
+func TestReactorRemovePeerEvictsIncompleteBundles(t *testing.T) {
+	config := cfg.TestConfig()
+	reactors := makeAndConnectReactors(config, 1)
+	reactor := reactors[0]
+	defer func() {
+		if err := reactor.Stop(); err != nil {
+			assert.NoError(t, err)
+		}
+	}()
+	sidecar := reactor.sidecar
+
+	peer := mock.NewPeer(nil)
+	reactor.InitPeer(peer)
+	bInfo := TxInfo{DesiredHeight: 1, BundleId: 0, BundleSize: 2}
+	reactor.Receive(SidecarChannel, peer, sidecarMsgBytes(t, []byte{0x01}, bInfo))
+	bInfo = TxInfo{DesiredHeight: 1, BundleId: 1, BundleSize: 1}
+	reactor.Receive(SidecarChannel, peer, sidecarMsgBytes(t, []byte{0x02}, bInfo))
+	// bundles from no peer, e.g. over RPC, aren't the peer's
+	require.NoError(t, sidecar.AddTx([]byte{0x03}, TxInfo{DesiredHeight: 1, BundleId: 2, BundleSize: 2}))
+	require.Equal(t, 3, sidecar.Size())
+
+	reactor.RemovePeer(peer, nil)
+	assert.Equal(t, 2, sidecar.Size())
+	assert.True(t, sidecar.IsBundleComplete(1, 1))
+	_, ok := sidecar.loadBundle(1, 2)
+	assert.True(t, ok)
+}
+
+func TestReactorSyncBundles(t *testing.T) {
+	config := cfg.TestConfig()
+	// with broadcasting off, the only way for reactors[1] to learn about
+	// reactors[0]'s bundles is to ask for them
+	config.Mempool.Broadcast = false
+	const N = 2
+	reactors := make([]*Reactor, N)
+	logger := mempoolLogger()
+	for i := 0; i < N; i++ {
+		cc := proxy.NewLocalClientCreator(kvstore.NewApplication())
+		mempool, sidecar, cleanup := newMempoolWithApp(cc)
+		defer cleanup()
+		reactors[i] = NewReactor(config.Mempool, mempool, sidecar)
+		reactors[i].SetLogger(logger.With("validator", i))
+	}
+	ahead, behind := reactors[0], reactors[1]
+	createSidecarBundleAndTxs(t, ahead.sidecar, testBundleInfo{BundleSize: 3, DesiredHeight: 1, BundleId: 0})
+	createSidecarBundleAndTxs(t, ahead.sidecar, testBundleInfo{BundleSize: 2, DesiredHeight: 2, BundleId: 0})
+	require.Equal(t, 5, ahead.sidecar.Size())
+
+	// adding the peer syncs the bundles it held before the two connected
+	p2p.MakeConnectedSwitches(config.P2P, N, func(i int, s *p2p.Switch) *p2p.Switch {
+		s.AddReactor("MEMPOOL", reactors[i])
+		return s
+	}, p2p.Connect2Switches)
+	defer func() {
+		for _, r := range reactors {
+			if err := r.Stop(); err != nil {
+				assert.NoError(t, err)
+			}
+		}
+	}()
+	waitForSidecarSize(t, behind, ahead.sidecar.Size())
+	assert.Equal(t, 5, behind.sidecar.Size())
+
+	// bundles added after that aren't gossiped, so have to be asked for again
+	createSidecarBundleAndTxs(t, ahead.sidecar, testBundleInfo{BundleSize: 2, DesiredHeight: 1, BundleId: 1})
+	laterTxs := createSidecarBundleAndTxs(t, ahead.sidecar, testBundleInfo{BundleSize: 2, DesiredHeight: 2, BundleId: 1})
+	require.Equal(t, 9, ahead.sidecar.Size())
+	ensureNoTxs(t, behind, 100*time.Millisecond)
+	assert.Equal(t, 5, behind.sidecar.Size())
+
+	peer := behind.Switch.Peers().List()[0]
+
+	// only bundles at or above the requested height are replayed
+	require.True(t, behind.RequestSyncBundles(peer, 2))
+	waitForSidecarSize(t, behind, 5+len(laterTxs))
+	for _, tx := range laterTxs {
+		_, ok := behind.sidecar.txsMap.Load(TxKey(tx))
+		assert.True(t, ok)
+	}
+
+	// syncing again from further back fills in the rest, skipping what we have
+	require.True(t, behind.RequestSyncBundles(peer, 1))
+	waitForSidecarSize(t, behind, ahead.sidecar.Size())
+	assert.Equal(t, ahead.sidecar.Size(), behind.sidecar.Size())
+}
+
+func TestReactorBundleAcks(t *testing.T) {
+	config := cfg.TestConfig()
+	const N = 2
+	reactors := makeAndConnectReactors(config, N)
+	defer func() {
+		for _, r := range reactors {
+			if err := r.Stop(); err != nil {
+				assert.NoError(t, err)
+			}
+		}
+	}()
+
+	sender, receiver := reactors[0], reactors[1]
+	for _, r := range reactors {
+		sidecarConfig := r.sidecar.Config()
+		sidecarConfig.AckBundles = true
+		require.NoError(t, r.sidecar.UpdateConfig(sidecarConfig))
+	}
+	type ack struct {
+		peerID        p2p.ID
+		desiredHeight int64
+		bundleID      int64
+	}
+	acks := make(chan ack, 10)
+	sender.SetBundleAckHook(func(peerID p2p.ID, desiredHeight, bundleID int64) {
+		acks <- ack{peerID, desiredHeight, bundleID}
+	})
+
+	// acked once, when the forwarded bundle is complete
+	txs := createSidecarBundleAndTxs(t, sender.sidecar, testBundleInfo{BundleSize: 3, DesiredHeight: 1, BundleId: 0})
+	waitForSidecarSize(t, receiver, len(txs))
+	select {
+	case got := <-acks:
+		assert.Equal(t, ack{receiver.Switch.NodeInfo().ID(), 1, 0}, got)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the bundle ack")
+	}
+
+	// not when the receiver doesn't ack
+	sidecarConfig := receiver.sidecar.Config()
+	sidecarConfig.AckBundles = false
+	require.NoError(t, receiver.sidecar.UpdateConfig(sidecarConfig))
+	createSidecarBundleAndTxs(t, sender.sidecar, testBundleInfo{BundleSize: 1, DesiredHeight: 1, BundleId: 1})
+	waitForSidecarSize(t, receiver, len(txs)+1)
+	select {
+	case got := <-acks:
+		t.Fatalf("unexpected ack %v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// replayPeer is a mock peer counting the sidecar messages sent to it.
+type replayPeer struct {
+	*mock.Peer
+	numSent int32 // atomic
+}
+
+func (p *replayPeer) Send(chID byte, msgBytes []byte) bool {
+	if chID == SidecarChannel {
+		atomic.AddInt32(&p.numSent, 1)
+	}
+	return true
+}
+
+func TestReactorSyncBundlesOneReplayPerPeer(t *testing.T) {
+	config := cfg.TestConfig()
+	reactors := makeAndConnectReactors(config, 1)
+	reactor := reactors[0]
+	defer func() {
+		if err := reactor.Stop(); err != nil {
+			assert.NoError(t, err)
+		}
+	}()
+	createSidecarBundleAndTxs(t, reactor.sidecar, testBundleInfo{BundleSize: 2, DesiredHeight: 1, BundleId: 0})
+
+	peer := &replayPeer{Peer: mock.NewPeer(nil)}
+	reactor.InitPeer(peer)
+	request, err := (&memproto.MEVMessage{
+		Sum: &memproto.MEVMessage_SyncBundles{SyncBundles: &memproto.SyncBundles{FromHeight: 1}},
+	}).Marshal()
+	require.NoError(t, err)
+
+	// requests while a replay to the peer is running are ignored
+	require.True(t, reactor.startReplay(peer.ID()))
+	reactor.Receive(SidecarChannel, peer, request)
+	time.Sleep(100 * time.Millisecond)
+	assert.Zero(t, atomic.LoadInt32(&peer.numSent))
+
+	// and served once it's done
+	reactor.finishReplay(peer.ID())
+	reactor.Receive(SidecarChannel, peer, request)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&peer.numSent) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return reactor.startReplay(peer.ID()) }, time.Second, 10*time.Millisecond)
+}
+
+// ackPeer is a mock peer recording the bundle acks sent to it.
+type ackPeer struct {
+	*mock.Peer
+	mtx  sync.Mutex
+	acks []memproto.AckBundle
+}
+
+func (p *ackPeer) TrySend(chID byte, msgBytes []byte) bool {
+	var msg memproto.MEVMessage
+	if chID == SidecarChannel && msg.Unmarshal(msgBytes) == nil && msg.GetAckBundle() != nil {
+		p.mtx.Lock()
+		p.acks = append(p.acks, *msg.GetAckBundle())
+		p.mtx.Unlock()
+	}
+	return true
+}
+
+func (p *ackPeer) Acks() []memproto.AckBundle {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return append([]memproto.AckBundle(nil), p.acks...)
+}
+
+func TestReactorBundleAcksPerSender(t *testing.T) {
+	config := cfg.TestConfig()
+	config.Sidecar.NamespaceBundlesBySender = true
+	config.Sidecar.AckBundles = true
+	appConn, err := proxy.NewLocalClientCreator(kvstore.NewApplication()).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop() // nolint:errcheck
+	sidecar := NewCListSidecar(config.Sidecar, 0)
+	mempool := NewCListMempool(config.Mempool, appConn, 0, WithSidecar(sidecar))
+	reactor := NewReactor(config.Mempool, mempool, sidecar)
+	reactor.SetLogger(mempoolLogger())
+
+	first, second := &ackPeer{Peer: mock.NewPeer(nil)}, &ackPeer{Peer: mock.NewPeer(nil)}
+	reactor.InitPeer(first)
+	reactor.InitPeer(second)
+
+	// both peers send a bundle with the same id, the first one completes
+	reactor.Receive(SidecarChannel, first, sidecarMsgBytes(t, []byte{0x01}, TxInfo{DesiredHeight: 1, BundleId: 0, BundleSize: 1}))
+	assert.Equal(t, []memproto.AckBundle{{DesiredHeight: 1, BundleId: 0}}, first.Acks())
+
+	// the second peer's bundle isn't acked until it's complete too
+	bInfo := TxInfo{DesiredHeight: 1, BundleId: 0, BundleSize: 2}
+	reactor.Receive(SidecarChannel, second, sidecarMsgBytes(t, []byte{0x02}, bInfo))
+	assert.Empty(t, second.Acks())
+	bInfo.BundleOrder = 1
+	reactor.Receive(SidecarChannel, second, sidecarMsgBytes(t, []byte{0x03}, bInfo))
+	assert.Equal(t, []memproto.AckBundle{{DesiredHeight: 1, BundleId: 0}}, second.Acks())
+	assert.Len(t, first.Acks(), 1)
+	assert.Equal(t, 2, sidecar.NumBundles())
+}
+
 func TestMempoolIDsBasic(t *testing.T) {
 	ids := newMempoolIDs()
 
@@ -478,6 +799,52 @@ func waitForSidecarTxsOnReactor(t *testing.T, txs types.Txs, reactor *Reactor, r
 	}
 }
 
+// waitForSidecarSize waits until the reactor's sidecar holds size txs
+func waitForSidecarSize(t *testing.T, reactor *Reactor, size int) {
+	timer := time.After(timeout)
+	for reactor.sidecar.Size() < size {
+		select {
+		case <-timer:
+			t.Fatalf("Timed out waiting for %d sidecar txs, have %d", size, reactor.sidecar.Size())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// sidecarMsgBytes encodes a SidecarChannel message carrying tx with the
+// bundle info in txInfo
+func sidecarMsgBytes(t *testing.T, tx types.Tx, txInfo TxInfo) []byte {
+	msg := memproto.MEVMessage{
+		Sum: &memproto.MEVMessage_Txs{
+			Txs: &memproto.Txs{Txs: [][]byte{tx}},
+		},
+		DesiredHeight: txInfo.DesiredHeight,
+		BundleId:      txInfo.BundleId,
+		BundleOrder:   txInfo.BundleOrder,
+		BundleSize:    txInfo.BundleSize,
+	}
+	bz, err := msg.Marshal()
+	require.NoError(t, err)
+	return bz
+}
+
+// bundleMempoolMsgBytes encodes a MempoolChannel message carrying tx with the
+// bundle info in txInfo
+func bundleMempoolMsgBytes(t *testing.T, tx types.Tx, txInfo TxInfo) []byte {
+	msg := memproto.Message{
+		Sum: &memproto.Message_Txs{
+			Txs: &memproto.Txs{Txs: [][]byte{tx}},
+		},
+		DesiredHeight: txInfo.DesiredHeight,
+		BundleId:      txInfo.BundleId,
+		BundleOrder:   txInfo.BundleOrder,
+		BundleSize:    txInfo.BundleSize,
+	}
+	bz, err := msg.Marshal()
+	require.NoError(t, err)
+	return bz
+}
+
 // ensure no txs on reactor after some timeout
 func ensureNoTxs(t *testing.T, reactor *Reactor, timeout time.Duration) {
 	time.Sleep(timeout) // wait for the txs in all mempools
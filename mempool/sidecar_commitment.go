@@ -0,0 +1,81 @@
+package mempool
+
+import (
+	"github.com/tendermint/tendermint/crypto/merkle"
+	"github.com/tendermint/tendermint/types"
+)
+
+// BundleHash returns the hash that commits to a bundle in ReapMerkleRoot:
+// the merkle root of its txs, in bundle order.
+func BundleHash(txs types.Txs) []byte {
+	return txs.Hash()
+}
+
+// BundleProof proves that a bundle is part of the bundles committed to by
+// ReapMerkleRoot.
+type BundleProof struct {
+	BundleId   int64
+	SenderID   uint16 // only set with namespace_bundles_by_sender, see ReapBundleProof
+	BundleHash []byte
+	Proof      *merkle.Proof
+}
+
+// Verify returns nil if the bundle is committed to by rootHash.
+func (bp BundleProof) Verify(rootHash []byte) error {
+	return bp.Proof.Verify(rootHash, bp.BundleHash)
+}
+
+// ReapMerkleRoot returns the merkle root over the bundles
+// ReapMaxBytesMaxGas(maxBytes, maxGas) would reap, with one leaf per bundle
+// (see BundleHash) in reap order. A light client given the root can check a
+// bundle was part of the auction with the proof from ReapBundleProof.
+// Like SimulateReap, it doesn't reap: no auction fires, and the sidecar is
+// left as is.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapMerkleRoot(maxBytes, maxGas int64) []byte {
+	_, bundleHashes := sc.reapBundleHashes(maxBytes, maxGas)
+	return merkle.HashFromByteSlices(bundleHashes)
+}
+
+// ReapBundleProof returns the root ReapMerkleRoot does, along with a proof
+// for the bundle with bundleId, and with senderID if bundles are namespaced by
+// sender (senderID is ignored otherwise). It returns ErrBundleNotReaped if
+// that bundle wouldn't be reaped.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) ReapBundleProof(maxBytes, maxGas, bundleId int64, senderID uint16) ([]byte, BundleProof, error) {
+	keys, bundleHashes := sc.reapBundleHashes(maxBytes, maxGas)
+	rootHash, proofs := merkle.ProofsFromByteSlices(bundleHashes)
+	if !sc.currentConfig().NamespaceBundlesBySender {
+		senderID = UnknownPeerID
+	}
+	for i, key := range keys {
+		if key.bundleId == bundleId && key.sender == senderID {
+			return rootHash, BundleProof{
+				BundleId:   bundleId,
+				SenderID:   key.sender,
+				BundleHash: bundleHashes[i],
+				Proof:      proofs[i],
+			}, nil
+		}
+	}
+	return rootHash, BundleProof{}, ErrBundleNotReaped{bundleId, sc.HeightForFiringAuction()}
+}
+
+// reapBundleHashes returns the keys and hashes of the bundles a reap would
+// take, in reap order, holding only the read lock while it selects them.
+func (sc *CListPriorityTxSidecar) reapBundleHashes(maxBytes, maxGas int64) (keys []Key, bundleHashes [][]byte) {
+	sc.updateMtx.RLock()
+	defer sc.updateMtx.RUnlock()
+
+	for _, selected := range sc.selectBundles(maxBytes, maxGas).bundles {
+		txs := make(types.Txs, len(selected.txs))
+		for i, scTx := range selected.txs {
+			txs[i] = scTx.tx
+		}
+		keys = append(keys, selected.key)
+		bundleHashes = append(bundleHashes, BundleHash(txs))
+	}
+	return keys, bundleHashes
+}
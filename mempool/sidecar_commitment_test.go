@@ -0,0 +1,103 @@
+package mempool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cfg "github.com/tendermint/tendermint/config"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestSidecarReapBundleProof(t *testing.T) {
+	sidecar := NewCListSidecar(cfg.TestSidecarConfig(), 0)
+	bundleTxs := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, DesiredHeight: 1, BundleId: 0})
+	bundleTxs1 := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 3, DesiredHeight: 1, BundleId: 1})
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, DesiredHeight: 1, BundleId: 2})
+	// incomplete, so never reaped
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, DesiredHeight: 1, BundleId: 3}, 0)
+
+	root := sidecar.ReapMerkleRoot(-1, -1)
+	require.NotEmpty(t, root)
+
+	rootHash, proof, err := sidecar.ReapBundleProof(-1, -1, 1, UnknownPeerID)
+	require.NoError(t, err)
+	assert.Equal(t, root, rootHash)
+	assert.EqualValues(t, 1, proof.BundleId)
+	assert.Equal(t, BundleHash(bundleTxs1), proof.BundleHash)
+	assert.NoError(t, proof.Verify(root))
+
+	// the proof doesn't hold for another bundle, or another set of bundles
+	proof.BundleHash = BundleHash(bundleTxs)
+	assert.Error(t, proof.Verify(root))
+	proof.BundleHash = BundleHash(bundleTxs1)
+	assert.Error(t, proof.Verify(sidecar.ReapMerkleRoot(22, -1)))
+
+	_, _, err = sidecar.ReapBundleProof(-1, -1, 3, UnknownPeerID)
+	assert.Equal(t, ErrBundleNotReaped{3, 1}, err)
+}
+
+func TestSidecarReapBundleProofNamespacedBySender(t *testing.T) {
+	config := cfg.TestSidecarConfig()
+	config.NamespaceBundlesBySender = true
+	sidecar := NewCListSidecar(config, 0)
+	// two senders use the same bundle id
+	firstTxs := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, PeerId: 1, DesiredHeight: 1, BundleId: 0})
+	secondTxs := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 2, DesiredHeight: 1, BundleId: 0})
+
+	root, proof, err := sidecar.ReapBundleProof(-1, -1, 0, 2)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, proof.SenderID)
+	assert.Equal(t, BundleHash(secondTxs), proof.BundleHash)
+	assert.NoError(t, proof.Verify(root))
+
+	_, proof, err = sidecar.ReapBundleProof(-1, -1, 0, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, proof.SenderID)
+	assert.Equal(t, BundleHash(firstTxs), proof.BundleHash)
+	assert.NoError(t, proof.Verify(root))
+
+	_, _, err = sidecar.ReapBundleProof(-1, -1, 0, 3)
+	assert.Equal(t, ErrBundleNotReaped{0, 1}, err)
+}
+
+func TestSidecarReapMerkleRootHasNoSideEffects(t *testing.T) {
+	eventBus := types.NewEventBus()
+	require.NoError(t, eventBus.Start())
+	defer eventBus.Stop() // nolint:errcheck
+
+	config := cfg.TestSidecarConfig()
+	config.CurrentHeightPolicy = cfg.SidecarCurrentHeightReject
+	config.StrictMode = true
+	sidecar := NewCListSidecar(config, 0, WithSidecarEventBus(eventBus))
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 0})
+	incomplete := testBundleInfo{BundleSize: 2, PeerId: 1, DesiredHeight: 1, BundleId: 1}
+	addTxToSidecar(t, sidecar, incomplete, 0)
+
+	sub, err := eventBus.Subscribe(context.Background(), "test", tmquery.MustParse("tm.event CONTAINS 'Sidecar'"), 10)
+	require.NoError(t, err)
+
+	// committing to the auction, then proving a bundle, doesn't reap
+	require.NotEmpty(t, sidecar.ReapMerkleRoot(-1, -1))
+	_, _, err = sidecar.ReapBundleProof(-1, -1, 0, 1)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 1, sidecar.HeightForFiringAuction())
+	assert.Empty(t, sidecar.LastReapWinners())
+	assert.Empty(t, sidecar.LastReapAudit())
+	assert.Empty(t, sidecar.FiredHeights(0))
+	assert.Zero(t, sidecar.PeerBundleStats()[1].ReapedBundles)
+	select {
+	case msg := <-sub.Out():
+		t.Fatalf("unexpected event %v", msg.Data())
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// the incomplete bundle isn't strict evicted, and the height is still open
+	addTxToSidecar(t, sidecar, incomplete, 1)
+	assert.Len(t, sidecar.ReapMaxTxs(), 4)
+}
@@ -0,0 +1,138 @@
+package mempool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	auto "github.com/tendermint/tendermint/libs/autofile"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	"github.com/tendermint/tendermint/libs/protoio"
+)
+
+// bundleExportFile is the name of the file accepted bundles are appended to,
+// in SidecarConfig.BundleExportDir. Rotated files get an index appended.
+const bundleExportFile = "bundles"
+
+// bundleExport is the file accepted bundles are appended to, see
+// InitBundleExport.
+type bundleExport struct {
+	head        *auto.AutoFile
+	path        string
+	maxFileSize int64
+	nextIndex   int // of the next rotated file
+}
+
+// InitBundleExport starts appending every bundle the sidecar accepts to a
+// file in SidecarConfig.BundleExportDir, for post-mortem analysis. Each order
+// is written as the length-delimited MEVMessage it's gossiped as, in bundle
+// order. Once the file reaches SidecarConfig.BundleExportMaxFileSize, it's
+// rotated between two bundles, so a bundle is never split across files.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) InitBundleExport() error {
+	config := sc.currentConfig()
+	dir := config.BundleExportDir()
+
+	const perm = 0700
+	if err := tmos.EnsureDir(dir, perm); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, bundleExportFile)
+	head, err := auto.OpenAutoFile(path)
+	if err != nil {
+		return fmt.Errorf("can't open autofile %s: %w", path, err)
+	}
+
+	export := &bundleExport{head: head, path: path, maxFileSize: config.BundleExportMaxFileSize}
+	// carry on after the files rotated by a previous run
+	for tmos.FileExists(export.rotatedPath(export.nextIndex)) {
+		export.nextIndex++
+	}
+
+	sc.exportMtx.Lock()
+	defer sc.exportMtx.Unlock()
+	sc.export = export
+	return nil
+}
+
+// CloseBundleExport stops the export started by InitBundleExport.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) CloseBundleExport() {
+	sc.exportMtx.Lock()
+	defer sc.exportMtx.Unlock()
+	if sc.export == nil {
+		return
+	}
+	if err := sc.export.head.Close(); err != nil {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: CloseBundleExport(): error closing %s: %v", sc.export.path, err))
+	}
+	sc.export = nil
+}
+
+// exportBundle appends bundle to the export file, if InitBundleExport was
+// called, rotating it once it's grown past its max size. A failed export is
+// logged, not returned: it never costs a bundle its place in the sidecar.
+//
+// Safe for concurrent use by multiple goroutines.
+func (sc *CListPriorityTxSidecar) exportBundle(bundle *Bundle) {
+	sc.exportMtx.Lock()
+	defer sc.exportMtx.Unlock()
+	if sc.export == nil {
+		return
+	}
+
+	w := protoio.NewDelimitedWriter(sc.export.head)
+	orderBase := int64(sc.currentConfig().BundleOrderBase)
+	for bundleOrder := int64(0); bundleOrder < bundle.enforcedSize; bundleOrder++ {
+		scTx, ok := bundle.orderedTxsMap.Load(bundleOrder)
+		if !ok {
+			continue
+		}
+		if _, err := w.WriteMsg(newSidecarTxMessage(scTx.(*SidecarTx), orderBase)); err != nil {
+			fmt.Println(fmt.Sprintf("[mev-tendermint]: exportBundle(): failed exporting bundle with id %d at height %d: %v", bundle.bundleId, bundle.desiredHeight, err))
+			return
+		}
+	}
+
+	if err := sc.export.rotateIfFull(); err != nil {
+		fmt.Println(fmt.Sprintf("[mev-tendermint]: exportBundle(): failed rotating %s: %v", sc.export.path, err))
+	}
+	fmt.Println(fmt.Sprintf("[mev-tendermint]: exportBundle(): exported bundle with id %d at height %d, with %d txs", bundle.bundleId, bundle.desiredHeight, bundle.enforcedSize))
+}
+
+// rotatedPath returns the path of the rotated file with index.
+func (e *bundleExport) rotatedPath(index int) string {
+	return fmt.Sprintf("%s.%03d", e.path, index)
+}
+
+// rotateIfFull moves the head file aside once it's reached maxFileSize, so
+// the next bundle starts a new one.
+func (e *bundleExport) rotateIfFull() error {
+	if e.maxFileSize <= 0 {
+		return nil
+	}
+	size, err := e.head.Size()
+	if err != nil {
+		return err
+	}
+	if size < e.maxFileSize {
+		return nil
+	}
+
+	if err := e.head.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(e.path, e.rotatedPath(e.nextIndex)); err != nil {
+		return err
+	}
+	e.nextIndex++
+	head, err := auto.OpenAutoFile(e.path)
+	if err != nil {
+		return err
+	}
+	e.head = head
+	return nil
+}
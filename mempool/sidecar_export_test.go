@@ -0,0 +1,82 @@
+package mempool
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/protoio"
+	protomem "github.com/tendermint/tendermint/proto/tendermint/mempool"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestSidecarBundleExport(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "sidecar-export-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(rootDir)
+
+	config := cfg.TestSidecarConfig()
+	config.RootDir = rootDir
+	config.BundleExportPath = "export"
+	// an order of a 20 byte tx takes 31 bytes
+	config.BundleExportMaxFileSize = 40
+	sidecar := NewCListSidecar(config, 0)
+	require.NoError(t, sidecar.InitBundleExport())
+	defer sidecar.CloseBundleExport()
+
+	bundle0 := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 2, DesiredHeight: 1, BundleId: 0})
+	bundle1 := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, DesiredHeight: 1, BundleId: 1})
+	// incomplete, so never exported
+	addTxToSidecar(t, sidecar, testBundleInfo{BundleSize: 2, DesiredHeight: 1, BundleId: 2}, 0)
+	bundle3 := createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, DesiredHeight: 1, BundleId: 3})
+
+	readExport := func(name string) []protomem.MEVMessage {
+		f, err := os.Open(filepath.Join(rootDir, "export", name))
+		require.NoError(t, err)
+		defer f.Close()
+		r := protoio.NewDelimitedReader(f, 1024)
+		msgs := make([]protomem.MEVMessage, 0)
+		for {
+			var msg protomem.MEVMessage
+			if _, err := r.ReadMsg(&msg); err == io.EOF {
+				return msgs
+			} else if err != nil {
+				require.NoError(t, err)
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	orders := func(bundleId int64, txs types.Txs) []protomem.MEVMessage {
+		msgs := make([]protomem.MEVMessage, len(txs))
+		for i, tx := range txs {
+			msgs[i] = protomem.MEVMessage{
+				Sum:           &protomem.MEVMessage_Txs{Txs: &protomem.Txs{Txs: [][]byte{tx}}},
+				DesiredHeight: 1,
+				BundleId:      bundleId,
+				BundleOrder:   int64(i),
+				BundleSize:    int64(len(txs)),
+			}
+		}
+		return msgs
+	}
+
+	// the first bundle fills a file on its own, the next two share one, and
+	// the head is left empty
+	assert.Equal(t, orders(0, bundle0), readExport("bundles.000"))
+	assert.Equal(t, append(orders(1, bundle1), orders(3, bundle3)...), readExport("bundles.001"))
+	assert.Empty(t, readExport("bundles"))
+
+	// once closed, nothing more is exported
+	sidecar.CloseBundleExport()
+	createSidecarBundleAndTxs(t, sidecar, testBundleInfo{BundleSize: 1, DesiredHeight: 1, BundleId: 4})
+	assert.Empty(t, readExport("bundles"))
+	files, err := filepath.Glob(filepath.Join(rootDir, "export", "*"))
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+}
@@ -211,6 +211,7 @@ type Node struct {
 	bcReactor         p2p.Reactor       // for fast-syncing
 	mempoolReactor    *mempl.Reactor    // for gossipping transactions
 	mempool           mempl.Mempool
+	sidecar           *mempl.CListPriorityTxSidecar
 	stateSync         bool                    // whether the node should state sync on startup
 	stateSyncReactor  *statesync.Reactor      // for hosting and restoring state sync snapshots
 	stateSyncProvider statesync.StateProvider // provides state data for bootstrapping a node
@@ -362,7 +363,23 @@ func onlyValidatorIsUs(state sm.State, pubKey crypto.PubKey) bool {
 }
 
 func createMempoolAndSidecarAndMempoolReactor(config *cfg.Config, proxyApp proxy.AppConns,
-	state sm.State, memplMetrics *mempl.Metrics, logger log.Logger) (*mempl.Reactor, *mempl.CListMempool, *mempl.CListPriorityTxSidecar) {
+	state sm.State, memplMetrics *mempl.Metrics, eventBus *types.EventBus, logger log.Logger) (*mempl.Reactor, *mempl.CListMempool, *mempl.CListPriorityTxSidecar, error) {
+
+	sidecarOptions := []mempl.CListSidecarOption{
+		mempl.WithSidecarMetrics(memplMetrics),
+		mempl.WithSidecarEventBus(eventBus),
+	}
+	if config.Sidecar.CheckTxs {
+		sidecarOptions = append(sidecarOptions, mempl.WithSidecarProxyAppConn(proxyApp.Mempool()))
+	}
+	sidecar, err := mempl.NewCListSidecarE(
+		config.Sidecar,
+		state.LastBlockHeight,
+		sidecarOptions...,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
 	mempool := mempl.NewCListMempool(
 		config.Mempool,
@@ -371,20 +388,24 @@ func createMempoolAndSidecarAndMempoolReactor(config *cfg.Config, proxyApp proxy
 		mempl.WithMetrics(memplMetrics),
 		mempl.WithPreCheck(sm.TxPreCheck(state)),
 		mempl.WithPostCheck(sm.TxPostCheck(state)),
-	)
-
-	sidecar := mempl.NewCListSidecar(
-		state.LastBlockHeight,
+		mempl.WithSidecar(sidecar),
 	)
 
 	mempoolLogger := logger.With("module", "mempool")
 	mempoolReactor := mempl.NewReactor(config.Mempool, mempool, sidecar)
 	mempoolReactor.SetLogger(mempoolLogger)
 
+	allowlist := splitAndTrimEmpty(config.Sidecar.AllowlistPeerIDs, ",", " ")
+	allowlistIDs := make([]p2p.ID, len(allowlist))
+	for i, id := range allowlist {
+		allowlistIDs[i] = p2p.ID(id)
+	}
+	mempoolReactor.SetSidecarPeerAllowlist(allowlistIDs)
+
 	if config.Consensus.WaitForTxs() {
 		mempool.EnableTxsAvailable()
 	}
-	return mempoolReactor, mempool, sidecar
+	return mempoolReactor, mempool, sidecar, nil
 }
 
 func createEvidenceReactor(config *cfg.Config, dbProvider DBProvider,
@@ -431,6 +452,7 @@ func createConsensusReactor(config *cfg.Config,
 	blockExec *sm.BlockExecutor,
 	blockStore sm.BlockStore,
 	mempool *mempl.CListMempool,
+	sidecar *mempl.CListPriorityTxSidecar,
 	evidencePool *evidence.Pool,
 	privValidator types.PrivValidator,
 	csMetrics *cs.Metrics,
@@ -451,7 +473,10 @@ func createConsensusReactor(config *cfg.Config,
 	if privValidator != nil {
 		consensusState.SetPrivValidator(privValidator)
 	}
-	consensusReactor := cs.NewReactor(consensusState, waitSync, cs.ReactorMetrics(csMetrics))
+	// the sidecar turns bundles away until the node is done syncing
+	sidecar.SetSyncing(waitSync)
+	consensusReactor := cs.NewReactor(consensusState, waitSync, cs.ReactorMetrics(csMetrics),
+		cs.ReactorOnSwitchToConsensus(func() { sidecar.SetSyncing(false) }))
 	consensusReactor.SetLogger(consensusLogger)
 	// services which will be publishing and/or subscribing for messages (events)
 	// consensusReactor will set it on consensusState and blockExecutor
@@ -776,7 +801,10 @@ func NewNode(config *cfg.Config,
 	csMetrics, p2pMetrics, memplMetrics, smMetrics := metricsProvider(genDoc.ChainID)
 
 	// Make MempoolReactor
-	mempoolReactor, mempool, sidecar := createMempoolAndSidecarAndMempoolReactor(config, proxyApp, state, memplMetrics, logger)
+	mempoolReactor, mempool, sidecar, err := createMempoolAndSidecarAndMempoolReactor(config, proxyApp, state, memplMetrics, eventBus, logger)
+	if err != nil {
+		return nil, err
+	}
 
 	// Make Evidence Reactor
 	evidenceReactor, evidencePool, err := createEvidenceReactor(config, dbProvider, stateDB, blockStore, logger)
@@ -809,7 +837,7 @@ func NewNode(config *cfg.Config,
 		csMetrics.FastSyncing.Set(1)
 	}
 	consensusReactor, consensusState := createConsensusReactor(
-		config, state, blockExec, blockStore, mempool, evidencePool,
+		config, state, blockExec, blockStore, mempool, sidecar, evidencePool,
 		privValidator, csMetrics, stateSync || fastSync, eventBus, consensusLogger,
 	)
 
@@ -898,6 +926,7 @@ func NewNode(config *cfg.Config,
 		bcReactor:        bcReactor,
 		mempoolReactor:   mempoolReactor,
 		mempool:          mempool,
+		sidecar:          sidecar,
 		consensusState:   consensusState,
 		consensusReactor: consensusReactor,
 		stateSyncReactor: stateSyncReactor,
@@ -983,6 +1012,13 @@ func (n *Node) OnStart() error {
 		}
 	}
 
+	if n.config.Sidecar.BundleExportEnabled() {
+		err = n.sidecar.InitBundleExport()
+		if err != nil {
+			return fmt.Errorf("init sidecar bundle export: %w", err)
+		}
+	}
+
 	// Start the switch (the P2P server).
 	err = n.sw.Start()
 	if err != nil {
@@ -1035,6 +1071,11 @@ func (n *Node) OnStop() {
 		n.mempool.CloseWAL()
 	}
 
+	// stop sidecar bundle export
+	if n.config.Sidecar.BundleExportEnabled() {
+		n.sidecar.CloseBundleExport()
+	}
+
 	if err := n.transport.Close(); err != nil {
 		n.Logger.Error("Error closing transport", "err", err)
 	}
@@ -255,6 +255,7 @@ func TestCreateProposalBlock(t *testing.T) {
 
 	// Make Sidecar
 	sidecar := mempl.NewCListSidecar(
+		config.Sidecar,
 		state.LastBlockHeight,
 	)
 
@@ -353,6 +354,7 @@ func TestMaxProposalBlockSize(t *testing.T) {
 
 	// Make Sidecar
 	sidecar := mempl.NewCListSidecar(
+		config.Sidecar,
 		state.LastBlockHeight,
 	)
 
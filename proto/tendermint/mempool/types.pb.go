@@ -66,10 +66,19 @@ func (m *Txs) GetTxs() [][]byte {
 	return nil
 }
 
+// Message is gossiped over the mempool channel. Txs with a bundle_size are
+// orders of a sidecar bundle, routed to the sidecar rather than the mempool.
+// The bundle fields are receive-only: they are set by external submitters,
+// never by the mempool's own broadcast, which relays bundles over the sidecar
+// channel instead.
 type Message struct {
 	// Types that are valid to be assigned to Sum:
 	//	*Message_Txs
-	Sum isMessage_Sum `protobuf_oneof:"sum"`
+	Sum           isMessage_Sum `protobuf_oneof:"sum"`
+	DesiredHeight int64         `protobuf:"varint,2,opt,name=desired_height,json=desiredHeight,proto3" json:"desired_height,omitempty"`
+	BundleId      int64         `protobuf:"varint,3,opt,name=bundle_id,json=bundleId,proto3" json:"bundle_id,omitempty"`
+	BundleOrder   int64         `protobuf:"varint,4,opt,name=bundle_order,json=bundleOrder,proto3" json:"bundle_order,omitempty"`
+	BundleSize    int64         `protobuf:"varint,5,opt,name=bundle_size,json=bundleSize,proto3" json:"bundle_size,omitempty"`
 }
 
 func (m *Message) Reset()         { *m = Message{} }
@@ -131,6 +140,34 @@ func (m *Message) GetTxs() *Txs {
 	return nil
 }
 
+func (m *Message) GetDesiredHeight() int64 {
+	if m != nil {
+		return m.DesiredHeight
+	}
+	return 0
+}
+
+func (m *Message) GetBundleId() int64 {
+	if m != nil {
+		return m.BundleId
+	}
+	return 0
+}
+
+func (m *Message) GetBundleOrder() int64 {
+	if m != nil {
+		return m.BundleOrder
+	}
+	return 0
+}
+
+func (m *Message) GetBundleSize() int64 {
+	if m != nil {
+		return m.BundleSize
+	}
+	return 0
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Message) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -138,9 +175,111 @@ func (*Message) XXX_OneofWrappers() []interface{} {
 	}
 }
 
+// SyncBundles asks a peer to replay every sidecar bundle it holds with a
+// desired height at or above from_height.
+type SyncBundles struct {
+	FromHeight int64 `protobuf:"varint,1,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+}
+
+func (m *SyncBundles) Reset()         { *m = SyncBundles{} }
+func (m *SyncBundles) String() string { return proto.CompactTextString(m) }
+func (*SyncBundles) ProtoMessage()    {}
+func (*SyncBundles) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2af51926fdbcbc05, []int{2}
+}
+func (m *SyncBundles) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SyncBundles) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SyncBundles.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SyncBundles) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SyncBundles.Merge(m, src)
+}
+func (m *SyncBundles) XXX_Size() int {
+	return m.Size()
+}
+func (m *SyncBundles) XXX_DiscardUnknown() {
+	xxx_messageInfo_SyncBundles.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SyncBundles proto.InternalMessageInfo
+
+func (m *SyncBundles) GetFromHeight() int64 {
+	if m != nil {
+		return m.FromHeight
+	}
+	return 0
+}
+
+// AckBundle acknowledges receipt of a forwarded sidecar bundle back to the
+// peer that sent it.
+type AckBundle struct {
+	DesiredHeight int64 `protobuf:"varint,1,opt,name=desired_height,json=desiredHeight,proto3" json:"desired_height,omitempty"`
+	BundleId      int64 `protobuf:"varint,2,opt,name=bundle_id,json=bundleId,proto3" json:"bundle_id,omitempty"`
+}
+
+func (m *AckBundle) Reset()         { *m = AckBundle{} }
+func (m *AckBundle) String() string { return proto.CompactTextString(m) }
+func (*AckBundle) ProtoMessage()    {}
+func (*AckBundle) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2af51926fdbcbc05, []int{3}
+}
+func (m *AckBundle) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AckBundle) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AckBundle.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AckBundle) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AckBundle.Merge(m, src)
+}
+func (m *AckBundle) XXX_Size() int {
+	return m.Size()
+}
+func (m *AckBundle) XXX_DiscardUnknown() {
+	xxx_messageInfo_AckBundle.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AckBundle proto.InternalMessageInfo
+
+func (m *AckBundle) GetDesiredHeight() int64 {
+	if m != nil {
+		return m.DesiredHeight
+	}
+	return 0
+}
+
+func (m *AckBundle) GetBundleId() int64 {
+	if m != nil {
+		return m.BundleId
+	}
+	return 0
+}
+
 type MEVMessage struct {
 	// Types that are valid to be assigned to Sum:
 	//	*MEVMessage_Txs
+	//	*MEVMessage_SyncBundles
+	//	*MEVMessage_AckBundle
 	Sum           isMEVMessage_Sum `protobuf_oneof:"sum"`
 	DesiredHeight int64            `protobuf:"varint,2,opt,name=desired_height,json=desiredHeight,proto3" json:"desired_height,omitempty"`
 	BundleId      int64            `protobuf:"varint,3,opt,name=bundle_id,json=bundleId,proto3" json:"bundle_id,omitempty"`
@@ -152,7 +291,7 @@ func (m *MEVMessage) Reset()         { *m = MEVMessage{} }
 func (m *MEVMessage) String() string { return proto.CompactTextString(m) }
 func (*MEVMessage) ProtoMessage()    {}
 func (*MEVMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2af51926fdbcbc05, []int{2}
+	return fileDescriptor_2af51926fdbcbc05, []int{4}
 }
 func (m *MEVMessage) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -190,8 +329,16 @@ type isMEVMessage_Sum interface {
 type MEVMessage_Txs struct {
 	Txs *Txs `protobuf:"bytes,1,opt,name=txs,proto3,oneof" json:"txs,omitempty"`
 }
+type MEVMessage_SyncBundles struct {
+	SyncBundles *SyncBundles `protobuf:"bytes,6,opt,name=sync_bundles,json=syncBundles,proto3,oneof" json:"sync_bundles,omitempty"`
+}
+type MEVMessage_AckBundle struct {
+	AckBundle *AckBundle `protobuf:"bytes,7,opt,name=ack_bundle,json=ackBundle,proto3,oneof" json:"ack_bundle,omitempty"`
+}
 
-func (*MEVMessage_Txs) isMEVMessage_Sum() {}
+func (*MEVMessage_Txs) isMEVMessage_Sum()         {}
+func (*MEVMessage_SyncBundles) isMEVMessage_Sum() {}
+func (*MEVMessage_AckBundle) isMEVMessage_Sum()   {}
 
 func (m *MEVMessage) GetSum() isMEVMessage_Sum {
 	if m != nil {
@@ -207,6 +354,20 @@ func (m *MEVMessage) GetTxs() *Txs {
 	return nil
 }
 
+func (m *MEVMessage) GetSyncBundles() *SyncBundles {
+	if x, ok := m.GetSum().(*MEVMessage_SyncBundles); ok {
+		return x.SyncBundles
+	}
+	return nil
+}
+
+func (m *MEVMessage) GetAckBundle() *AckBundle {
+	if x, ok := m.GetSum().(*MEVMessage_AckBundle); ok {
+		return x.AckBundle
+	}
+	return nil
+}
+
 func (m *MEVMessage) GetDesiredHeight() int64 {
 	if m != nil {
 		return m.DesiredHeight
@@ -239,37 +400,47 @@ func (m *MEVMessage) GetBundleSize() int64 {
 func (*MEVMessage) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
 		(*MEVMessage_Txs)(nil),
+		(*MEVMessage_SyncBundles)(nil),
+		(*MEVMessage_AckBundle)(nil),
 	}
 }
 
 func init() {
 	proto.RegisterType((*Txs)(nil), "tendermint.mempool.Txs")
 	proto.RegisterType((*Message)(nil), "tendermint.mempool.Message")
+	proto.RegisterType((*SyncBundles)(nil), "tendermint.mempool.SyncBundles")
+	proto.RegisterType((*AckBundle)(nil), "tendermint.mempool.AckBundle")
 	proto.RegisterType((*MEVMessage)(nil), "tendermint.mempool.MEVMessage")
 }
 
 func init() { proto.RegisterFile("tendermint/mempool/types.proto", fileDescriptor_2af51926fdbcbc05) }
 
 var fileDescriptor_2af51926fdbcbc05 = []byte{
-	// 286 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x92, 0x2b, 0x49, 0xcd, 0x4b,
-	0x49, 0x2d, 0xca, 0xcd, 0xcc, 0x2b, 0xd1, 0xcf, 0x4d, 0xcd, 0x2d, 0xc8, 0xcf, 0xcf, 0xd1, 0x2f,
-	0xa9, 0x2c, 0x48, 0x2d, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x12, 0x42, 0xc8, 0xeb, 0x41,
-	0xe5, 0x95, 0xc4, 0xb9, 0x98, 0x43, 0x2a, 0x8a, 0x85, 0x04, 0xb8, 0x98, 0x4b, 0x2a, 0x8a, 0x25,
-	0x18, 0x15, 0x98, 0x35, 0x78, 0x82, 0x40, 0x4c, 0x25, 0x5b, 0x2e, 0x76, 0xdf, 0xd4, 0xe2, 0xe2,
-	0xc4, 0xf4, 0x54, 0x21, 0x6d, 0x98, 0x24, 0xa3, 0x06, 0xb7, 0x91, 0xb8, 0x1e, 0xa6, 0x29, 0x7a,
-	0x21, 0x15, 0xc5, 0x1e, 0x0c, 0x60, 0x7d, 0x4e, 0xac, 0x5c, 0xcc, 0xc5, 0xa5, 0xb9, 0x4a, 0x27,
-	0x18, 0xb9, 0xb8, 0x7c, 0x5d, 0xc3, 0xc8, 0x31, 0x42, 0x48, 0x95, 0x8b, 0x2f, 0x25, 0xb5, 0x38,
-	0xb3, 0x28, 0x35, 0x25, 0x3e, 0x23, 0x35, 0x33, 0x3d, 0xa3, 0x44, 0x82, 0x49, 0x81, 0x51, 0x83,
-	0x39, 0x88, 0x17, 0x2a, 0xea, 0x01, 0x16, 0x14, 0x92, 0xe6, 0xe2, 0x4c, 0x2a, 0xcd, 0x4b, 0xc9,
-	0x49, 0x8d, 0xcf, 0x4c, 0x91, 0x60, 0x06, 0xab, 0xe0, 0x80, 0x08, 0x78, 0xa6, 0x08, 0x29, 0x72,
-	0xf1, 0x40, 0x25, 0xf3, 0x8b, 0x52, 0x52, 0x8b, 0x24, 0x58, 0xc0, 0xf2, 0xdc, 0x10, 0x31, 0x7f,
-	0x90, 0x90, 0x90, 0x3c, 0x17, 0x94, 0x1b, 0x5f, 0x9c, 0x59, 0x95, 0x2a, 0xc1, 0x0a, 0x56, 0xc1,
-	0x05, 0x11, 0x0a, 0xce, 0xac, 0x4a, 0x85, 0x7a, 0xc5, 0x29, 0xf8, 0xc4, 0x23, 0x39, 0xc6, 0x0b,
-	0x8f, 0xe4, 0x18, 0x1f, 0x3c, 0x92, 0x63, 0x9c, 0xf0, 0x58, 0x8e, 0xe1, 0xc2, 0x63, 0x39, 0x86,
-	0x1b, 0x8f, 0xe5, 0x18, 0xa2, 0x2c, 0xd3, 0x33, 0x4b, 0x32, 0x4a, 0x93, 0xf4, 0x92, 0xf3, 0x73,
-	0xf5, 0x91, 0xc2, 0x1e, 0x89, 0x09, 0x0e, 0x78, 0x7d, 0xcc, 0x78, 0x49, 0x62, 0x03, 0xcb, 0x18,
-	0x03, 0x02, 0x00, 0x00, 0xff, 0xff, 0x93, 0x24, 0x04, 0x86, 0xb4, 0x01, 0x00, 0x00,
+	// 380 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x53, 0xc1, 0x6a, 0xea, 0x40,
+	0x14, 0xcd, 0x98, 0xa7, 0x3e, 0x6f, 0x7c, 0x8f, 0xc7, 0x6c, 0x0c, 0x3c, 0x1a, 0x6d, 0xa0, 0x20,
+	0x14, 0x12, 0x68, 0x57, 0xdd, 0x14, 0x2a, 0x2d, 0xa4, 0x0b, 0x11, 0xa2, 0x74, 0xd1, 0x4d, 0xd0,
+	0x64, 0xaa, 0x41, 0x93, 0x91, 0xcc, 0x08, 0xea, 0x57, 0xf4, 0xa7, 0x0a, 0x5d, 0x15, 0x97, 0x5d,
+	0x16, 0xfd, 0x91, 0x92, 0x99, 0xd8, 0xa6, 0x28, 0x2d, 0x74, 0xd3, 0xdd, 0x9d, 0x73, 0xee, 0x39,
+	0xdc, 0x73, 0x87, 0x0b, 0x06, 0x27, 0x71, 0x40, 0x92, 0x28, 0x8c, 0xb9, 0x1d, 0x91, 0x68, 0x4a,
+	0xe9, 0xc4, 0xe6, 0x8b, 0x29, 0x61, 0xd6, 0x34, 0xa1, 0x9c, 0x62, 0xfc, 0xce, 0x5b, 0x19, 0x6f,
+	0xd6, 0x40, 0xed, 0xcd, 0x19, 0xfe, 0x07, 0x2a, 0x9f, 0x33, 0x1d, 0x35, 0xd4, 0x66, 0xd5, 0x4d,
+	0x4b, 0xf3, 0x01, 0x41, 0xb9, 0x4d, 0x18, 0xeb, 0x0f, 0x09, 0x3e, 0xde, 0xb2, 0xa8, 0xa9, 0x9d,
+	0xd4, 0xac, 0x5d, 0x1b, 0xab, 0x37, 0x67, 0x8e, 0x22, 0x84, 0xf8, 0x08, 0xfe, 0x06, 0x84, 0x85,
+	0x09, 0x09, 0xbc, 0x11, 0x09, 0x87, 0x23, 0xae, 0x17, 0x1a, 0xa8, 0xa9, 0xba, 0x7f, 0x32, 0xd4,
+	0x11, 0x20, 0xfe, 0x0f, 0x95, 0xc1, 0x2c, 0x0e, 0x26, 0xc4, 0x0b, 0x03, 0x5d, 0x15, 0x1d, 0xbf,
+	0x25, 0x70, 0x1d, 0xe0, 0x43, 0xa8, 0x66, 0x24, 0x4d, 0x02, 0x92, 0xe8, 0xbf, 0x04, 0xaf, 0x49,
+	0xac, 0x93, 0x42, 0xb8, 0x0e, 0xd9, 0xd3, 0x63, 0xe1, 0x92, 0xe8, 0x45, 0xd1, 0x01, 0x12, 0xea,
+	0x86, 0x4b, 0xd2, 0x2a, 0x82, 0xca, 0x66, 0x91, 0x69, 0x81, 0xd6, 0x5d, 0xc4, 0x7e, 0x4b, 0x10,
+	0x2c, 0x95, 0xdd, 0x25, 0x34, 0xda, 0x8e, 0x86, 0xa4, 0x2c, 0x85, 0xe4, 0x5c, 0x66, 0x07, 0x2a,
+	0x17, 0xfe, 0x58, 0xb6, 0xef, 0xc9, 0x82, 0xbe, 0xcc, 0x52, 0xf8, 0x98, 0xc5, 0x7c, 0x2a, 0x00,
+	0xb4, 0xaf, 0x6e, 0xbe, 0xb5, 0xcb, 0x4b, 0xa8, 0xb2, 0x45, 0xec, 0x7b, 0xd2, 0x8c, 0xe9, 0x25,
+	0xa1, 0xaa, 0xef, 0x53, 0xe5, 0x42, 0x3a, 0x8a, 0xab, 0xb1, 0x5c, 0xe6, 0x73, 0x80, 0xbe, 0x3f,
+	0xce, 0x4c, 0xf4, 0xb2, 0xf0, 0x38, 0xd8, 0xe7, 0xf1, 0x16, 0xdc, 0x51, 0xdc, 0x4a, 0xff, 0x93,
+	0x2d, 0xfc, 0xe4, 0x8f, 0xb6, 0xba, 0x8f, 0x6b, 0x03, 0xad, 0xd6, 0x06, 0x7a, 0x59, 0x1b, 0xe8,
+	0x7e, 0x63, 0x28, 0xab, 0x8d, 0xa1, 0x3c, 0x6f, 0x0c, 0xe5, 0xf6, 0x6c, 0x18, 0xf2, 0xd1, 0x6c,
+	0x60, 0xf9, 0x34, 0xb2, 0x73, 0xb7, 0x90, 0x2b, 0xc5, 0x21, 0xd8, 0xbb, 0x77, 0x32, 0x28, 0x09,
+	0xe6, 0xf4, 0x75, 0x00, 0x4b, 0xd8, 0x4d, 0x20, 0x44, 0x03, 0x00, 0x00,
 }
 
 func (m *Txs) Marshal() (dAtA []byte, err error) {
@@ -324,6 +495,26 @@ func (m *Message) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.BundleSize != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.BundleSize))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.BundleOrder != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.BundleOrder))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.BundleId != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.BundleId))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.DesiredHeight != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.DesiredHeight))
+		i--
+		dAtA[i] = 0x10
+	}
 	if m.Sum != nil {
 		{
 			size := m.Sum.Size()
@@ -357,6 +548,67 @@ func (m *Message_Txs) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	}
 	return len(dAtA) - i, nil
 }
+func (m *SyncBundles) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SyncBundles) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SyncBundles) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.FromHeight != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.FromHeight))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AckBundle) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AckBundle) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AckBundle) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.BundleId != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.BundleId))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.DesiredHeight != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.DesiredHeight))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *MEVMessage) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -377,6 +629,15 @@ func (m *MEVMessage) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Sum != nil {
+		{
+			size := m.Sum.Size()
+			i -= size
+			if _, err := m.Sum.MarshalTo(dAtA[i:]); err != nil {
+				return 0, err
+			}
+		}
+	}
 	if m.BundleSize != 0 {
 		i = encodeVarintTypes(dAtA, i, uint64(m.BundleSize))
 		i--
@@ -397,15 +658,6 @@ func (m *MEVMessage) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x10
 	}
-	if m.Sum != nil {
-		{
-			size := m.Sum.Size()
-			i -= size
-			if _, err := m.Sum.MarshalTo(dAtA[i:]); err != nil {
-				return 0, err
-			}
-		}
-	}
 	return len(dAtA) - i, nil
 }
 
@@ -430,6 +682,48 @@ func (m *MEVMessage_Txs) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	}
 	return len(dAtA) - i, nil
 }
+func (m *MEVMessage_SyncBundles) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MEVMessage_SyncBundles) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.SyncBundles != nil {
+		{
+			size, err := m.SyncBundles.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTypes(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x32
+	}
+	return len(dAtA) - i, nil
+}
+func (m *MEVMessage_AckBundle) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MEVMessage_AckBundle) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.AckBundle != nil {
+		{
+			size, err := m.AckBundle.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTypes(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x3a
+	}
+	return len(dAtA) - i, nil
+}
 func encodeVarintTypes(dAtA []byte, offset int, v uint64) int {
 	offset -= sovTypes(v)
 	base := offset
@@ -465,6 +759,18 @@ func (m *Message) Size() (n int) {
 	if m.Sum != nil {
 		n += m.Sum.Size()
 	}
+	if m.DesiredHeight != 0 {
+		n += 1 + sovTypes(uint64(m.DesiredHeight))
+	}
+	if m.BundleId != 0 {
+		n += 1 + sovTypes(uint64(m.BundleId))
+	}
+	if m.BundleOrder != 0 {
+		n += 1 + sovTypes(uint64(m.BundleOrder))
+	}
+	if m.BundleSize != 0 {
+		n += 1 + sovTypes(uint64(m.BundleSize))
+	}
 	return n
 }
 
@@ -480,6 +786,33 @@ func (m *Message_Txs) Size() (n int) {
 	}
 	return n
 }
+func (m *SyncBundles) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.FromHeight != 0 {
+		n += 1 + sovTypes(uint64(m.FromHeight))
+	}
+	return n
+}
+
+func (m *AckBundle) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.DesiredHeight != 0 {
+		n += 1 + sovTypes(uint64(m.DesiredHeight))
+	}
+	if m.BundleId != 0 {
+		n += 1 + sovTypes(uint64(m.BundleId))
+	}
+	return n
+}
+
 func (m *MEVMessage) Size() (n int) {
 	if m == nil {
 		return 0
@@ -516,11 +849,35 @@ func (m *MEVMessage_Txs) Size() (n int) {
 	}
 	return n
 }
-
-func sovTypes(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozTypes(x uint64) (n int) {
+func (m *MEVMessage_SyncBundles) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.SyncBundles != nil {
+		l = m.SyncBundles.Size()
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	return n
+}
+func (m *MEVMessage_AckBundle) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.AckBundle != nil {
+		l = m.AckBundle.Size()
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	return n
+}
+
+func sovTypes(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozTypes(x uint64) (n int) {
 	return sovTypes(uint64((x << 1) ^ uint64((int64(x) >> 63))))
 }
 func (m *Txs) Unmarshal(dAtA []byte) error {
@@ -669,6 +1026,239 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 			}
 			m.Sum = &Message_Txs{v}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DesiredHeight", wireType)
+			}
+			m.DesiredHeight = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DesiredHeight |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BundleId", wireType)
+			}
+			m.BundleId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BundleId |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BundleOrder", wireType)
+			}
+			m.BundleOrder = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BundleOrder |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BundleSize", wireType)
+			}
+			m.BundleSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BundleSize |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SyncBundles) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SyncBundles: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SyncBundles: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FromHeight", wireType)
+			}
+			m.FromHeight = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FromHeight |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AckBundle) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AckBundle: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AckBundle: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DesiredHeight", wireType)
+			}
+			m.DesiredHeight = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DesiredHeight |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BundleId", wireType)
+			}
+			m.BundleId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BundleId |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -830,6 +1420,76 @@ func (m *MEVMessage) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SyncBundles", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &SyncBundles{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Sum = &MEVMessage_SyncBundles{v}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AckBundle", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &AckBundle{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Sum = &MEVMessage_AckBundle{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
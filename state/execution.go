@@ -255,11 +255,13 @@ func (blockExec *BlockExecutor) Commit(
 	)
 
 	// Update the sidecar
-	blockExec.sidecar.Update(
+	if err := blockExec.sidecar.Update(
 		block.Height,
 		block.Txs,
 		deliverTxResponses,
-	)
+	); err != nil {
+		blockExec.logger.Error("error updating sidecar", "height", block.Height, "err", err)
+	}
 
 	// Update mempool.
 	err = blockExec.mempool.Update(
@@ -378,7 +378,7 @@ func onlyValidatorIsUs(state sm.State, pubKey crypto.PubKey) bool {
 }
 
 func createMempoolAndSidecarAndMempoolReactor(config *cfg.Config, proxyApp proxy.AppConns,
-	state sm.State, memplMetrics *mempl.Metrics, logger log.Logger) (*mempl.Reactor, *mempl.CListMempool, *mempl.CListPriorityTxSidecar) {
+	state sm.State, memplMetrics *mempl.Metrics, logger log.Logger) (*mempl.Reactor, *mempl.CListMempool, *mempl.CListPriorityTxSidecar, error) {
 
 	mempool := mempl.NewCListMempool(
 		config.Mempool,
@@ -390,16 +390,20 @@ func createMempoolAndSidecarAndMempoolReactor(config *cfg.Config, proxyApp proxy
 	)
 	mempoolLogger := logger.With("module", "mempool")
 
-	sidecar := mempl.NewCListSidecar(
+	sidecar, err := mempl.NewCListSidecarE(
+		config.Sidecar,
 		state.LastBlockHeight,
 	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	mempoolReactor := mempl.NewReactor(config.Mempool, mempool, sidecar)
 	mempoolReactor.SetLogger(mempoolLogger)
 
 	if config.Consensus.WaitForTxs() {
 		mempool.EnableTxsAvailable()
 	}
-	return mempoolReactor, mempool, sidecar
+	return mempoolReactor, mempool, sidecar, nil
 }
 
 func createEvidenceReactor(config *cfg.Config, dbProvider DBProvider,
@@ -773,7 +777,10 @@ func NewNode(config *cfg.Config,
 	csMetrics, p2pMetrics, memplMetrics, smMetrics := metricsProvider(genDoc.ChainID)
 
 	// Make MempoolReactor
-	mempoolReactor, mempool, sidecar := createMempoolAndSidecarAndMempoolReactor(config, proxyApp, state, memplMetrics, logger)
+	mempoolReactor, mempool, sidecar, err := createMempoolAndSidecarAndMempoolReactor(config, proxyApp, state, memplMetrics, logger)
+	if err != nil {
+		return nil, err
+	}
 
 	// Make Evidence Reactor
 	evidenceReactor, evidencePool, err := createEvidenceReactor(config, dbProvider, stateDB, blockStore, logger)
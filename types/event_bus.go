@@ -227,6 +227,22 @@ func (b *EventBus) PublishEventValidatorSetUpdates(data EventDataValidatorSetUpd
 	return b.Publish(EventValidatorSetUpdates, data)
 }
 
+func (b *EventBus) PublishEventSidecarBundleAccepted(data EventDataSidecarBundle) error {
+	return b.Publish(EventSidecarBundleAccepted, data)
+}
+
+func (b *EventBus) PublishEventSidecarBundleReaped(data EventDataSidecarBundle) error {
+	return b.Publish(EventSidecarBundleReaped, data)
+}
+
+func (b *EventBus) PublishEventSidecarAuctionFired(data EventDataSidecarAuction) error {
+	return b.Publish(EventSidecarAuctionFired, data)
+}
+
+func (b *EventBus) PublishEventSidecarHeightStats(data EventDataSidecarHeightStats) error {
+	return b.Publish(EventSidecarHeightStats, data)
+}
+
 //-----------------------------------------------------------------------------
 type NopEventBus struct{}
 
@@ -306,3 +322,19 @@ func (NopEventBus) PublishEventLock(data EventDataRoundState) error {
 func (NopEventBus) PublishEventValidatorSetUpdates(data EventDataValidatorSetUpdates) error {
 	return nil
 }
+
+func (NopEventBus) PublishEventSidecarBundleAccepted(data EventDataSidecarBundle) error {
+	return nil
+}
+
+func (NopEventBus) PublishEventSidecarBundleReaped(data EventDataSidecarBundle) error {
+	return nil
+}
+
+func (NopEventBus) PublishEventSidecarAuctionFired(data EventDataSidecarAuction) error {
+	return nil
+}
+
+func (NopEventBus) PublishEventSidecarHeightStats(data EventDataSidecarHeightStats) error {
+	return nil
+}
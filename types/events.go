@@ -36,6 +36,14 @@ const (
 	EventUnlock           = "Unlock"
 	EventValidBlock       = "ValidBlock"
 	EventVote             = "Vote"
+
+	// Sidecar events.
+	// These are triggered from the mempool's sidecar, for tooling that
+	// follows bundles and auctions.
+	EventSidecarAuctionFired   = "SidecarAuctionFired"
+	EventSidecarBundleAccepted = "SidecarBundleAccepted"
+	EventSidecarBundleReaped   = "SidecarBundleReaped"
+	EventSidecarHeightStats    = "SidecarHeightStats"
 )
 
 // ENCODING / DECODING
@@ -56,6 +64,9 @@ func init() {
 	tmjson.RegisterType(EventDataVote{}, "tendermint/event/Vote")
 	tmjson.RegisterType(EventDataValidatorSetUpdates{}, "tendermint/event/ValidatorSetUpdates")
 	tmjson.RegisterType(EventDataString(""), "tendermint/event/ProposalString")
+	tmjson.RegisterType(EventDataSidecarBundle{}, "tendermint/event/SidecarBundle")
+	tmjson.RegisterType(EventDataSidecarAuction{}, "tendermint/event/SidecarAuction")
+	tmjson.RegisterType(EventDataSidecarHeightStats{}, "tendermint/event/SidecarHeightStats")
 }
 
 // Most event messages are basic types (a block, a transaction)
@@ -125,6 +136,33 @@ type EventDataValidatorSetUpdates struct {
 	ValidatorUpdates []*Validator `json:"validator_updates"`
 }
 
+// EventDataSidecarBundle describes a sidecar bundle that was accepted (i.e.
+// received all its orders) or reaped
+type EventDataSidecarBundle struct {
+	Height     int64 `json:"height"` // height the bundle is for
+	BundleId   int64 `json:"bundle_id"`
+	BundleSize int64 `json:"bundle_size"`
+
+	// mempool id of the peer that sent the first order of the bundle
+	SenderID uint16 `json:"sender_id"`
+}
+
+// EventDataSidecarAuction describes a reap of the sidecar's bundles for a height
+type EventDataSidecarAuction struct {
+	Height     int64 `json:"height"`
+	NumBundles int   `json:"num_bundles"`
+	NumTxs     int   `json:"num_txs"`
+}
+
+// EventDataSidecarHeightStats describes what the sidecar did for a height,
+// once it's committed
+type EventDataSidecarHeightStats struct {
+	Height         int64 `json:"height"`
+	ReapedBundles  int   `json:"reaped_bundles"`
+	ReapedBytes    int64 `json:"reaped_bytes"`
+	EvictedBundles int   `json:"evicted_bundles"`
+}
+
 // PUBSUB
 
 const (
@@ -143,22 +181,26 @@ const (
 )
 
 var (
-	EventQueryCompleteProposal    = QueryForEvent(EventCompleteProposal)
-	EventQueryLock                = QueryForEvent(EventLock)
-	EventQueryNewBlock            = QueryForEvent(EventNewBlock)
-	EventQueryNewBlockHeader      = QueryForEvent(EventNewBlockHeader)
-	EventQueryNewEvidence         = QueryForEvent(EventNewEvidence)
-	EventQueryNewRound            = QueryForEvent(EventNewRound)
-	EventQueryNewRoundStep        = QueryForEvent(EventNewRoundStep)
-	EventQueryPolka               = QueryForEvent(EventPolka)
-	EventQueryRelock              = QueryForEvent(EventRelock)
-	EventQueryTimeoutPropose      = QueryForEvent(EventTimeoutPropose)
-	EventQueryTimeoutWait         = QueryForEvent(EventTimeoutWait)
-	EventQueryTx                  = QueryForEvent(EventTx)
-	EventQueryUnlock              = QueryForEvent(EventUnlock)
-	EventQueryValidatorSetUpdates = QueryForEvent(EventValidatorSetUpdates)
-	EventQueryValidBlock          = QueryForEvent(EventValidBlock)
-	EventQueryVote                = QueryForEvent(EventVote)
+	EventQueryCompleteProposal      = QueryForEvent(EventCompleteProposal)
+	EventQueryLock                  = QueryForEvent(EventLock)
+	EventQueryNewBlock              = QueryForEvent(EventNewBlock)
+	EventQueryNewBlockHeader        = QueryForEvent(EventNewBlockHeader)
+	EventQueryNewEvidence           = QueryForEvent(EventNewEvidence)
+	EventQueryNewRound              = QueryForEvent(EventNewRound)
+	EventQueryNewRoundStep          = QueryForEvent(EventNewRoundStep)
+	EventQueryPolka                 = QueryForEvent(EventPolka)
+	EventQueryRelock                = QueryForEvent(EventRelock)
+	EventQuerySidecarAuctionFired   = QueryForEvent(EventSidecarAuctionFired)
+	EventQuerySidecarBundleAccepted = QueryForEvent(EventSidecarBundleAccepted)
+	EventQuerySidecarBundleReaped   = QueryForEvent(EventSidecarBundleReaped)
+	EventQuerySidecarHeightStats    = QueryForEvent(EventSidecarHeightStats)
+	EventQueryTimeoutPropose        = QueryForEvent(EventTimeoutPropose)
+	EventQueryTimeoutWait           = QueryForEvent(EventTimeoutWait)
+	EventQueryTx                    = QueryForEvent(EventTx)
+	EventQueryUnlock                = QueryForEvent(EventUnlock)
+	EventQueryValidatorSetUpdates   = QueryForEvent(EventValidatorSetUpdates)
+	EventQueryValidBlock            = QueryForEvent(EventValidBlock)
+	EventQueryVote                  = QueryForEvent(EventVote)
 )
 
 func EventQueryTxFor(tx Tx) tmpubsub.Query {
@@ -181,3 +223,11 @@ type BlockEventPublisher interface {
 type TxEventPublisher interface {
 	PublishEventTx(EventDataTx) error
 }
+
+// SidecarEventPublisher publishes all sidecar related events
+type SidecarEventPublisher interface {
+	PublishEventSidecarBundleAccepted(EventDataSidecarBundle) error
+	PublishEventSidecarBundleReaped(EventDataSidecarBundle) error
+	PublishEventSidecarAuctionFired(EventDataSidecarAuction) error
+	PublishEventSidecarHeightStats(EventDataSidecarHeightStats) error
+}